@@ -6,6 +6,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/abklabs/svmkit/pkg/firedancer"
 	"github.com/abklabs/svmkit/pkg/runner"
 	"github.com/abklabs/svmkit/pkg/runner/deb"
 	"github.com/abklabs/svmkit/pkg/runner/deployer"
@@ -23,6 +24,7 @@ type Build struct {
 	MakeMachine    string
 	MakeCFlags     string
 	MakeTarget     string
+	Variant        string
 }
 
 func (cmd *Build) Env() *runner.EnvBuilder {
@@ -33,6 +35,7 @@ func (cmd *Build) Env() *runner.EnvBuilder {
 	env.Set("FD_MAKE_MACHINE", cmd.MakeMachine)
 	env.Set("FD_MAKE_CFLAGS", cmd.MakeCFlags)
 	env.Set("FD_MAKE_TARGET", cmd.MakeTarget)
+	env.Set("FD_VARIANT", cmd.Variant)
 
 	return env
 }
@@ -40,6 +43,10 @@ func (cmd *Build) Env() *runner.EnvBuilder {
 func (cmd *Build) Check() error {
 	cmd.SetConfigDefaults()
 
+	if err := firedancer.Variant(cmd.Variant).Check(); err != nil {
+		return err
+	}
+
 	pkgGrp := deb.Package{}.MakePackageGroup("cmake", "alien")
 
 	if err := cmd.UpdatePackageGroup(pkgGrp); err != nil {
@@ -72,7 +79,7 @@ func (cmd *Build) AddToPayload(p *runner.Payload) error {
 
 var FDCmd = &cobra.Command{
 	Use:   "fd",
-	Short: "Build a Frankendancer Debian package",
+	Short: "Build a Frankendancer or Firedancer Debian package",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cwd, err := os.Getwd()
 		if err != nil {
@@ -101,11 +108,21 @@ var FDCmd = &cobra.Command{
 			return err
 		}
 
+		variant, err := flags.GetString("variant")
+		if err != nil {
+			return err
+		}
+
 		makeTarget, err := flags.GetString("make-target")
 		if err != nil {
 			return err
 		}
 
+		// Each variant has its own binary to build.
+		if !flags.Changed("make-target") && firedancer.Variant(variant) == firedancer.VariantFiredancer {
+			makeTarget = "firedancer"
+		}
+
 		dryRun, err := flags.GetBool("dry-run")
 
 		if err != nil {
@@ -119,6 +136,7 @@ var FDCmd = &cobra.Command{
 			MakeCFlags:     makeCFlags,
 			DepsFetchExtra: depsFetchExtra,
 			MakeTarget:     makeTarget,
+			Variant:        variant,
 		}
 
 		if err := runnerCommand.Check(); err != nil {
@@ -172,5 +190,6 @@ func init() {
 	flags.String("deps-fetch-extra", "+dev", "extra deps.sh fetch args")
 	flags.String("make-machine", "linux_gcc_x86_64", "MACHINE env var passed to make")
 	flags.String("make-cflags", "", "extra make CFLAGS")
-	flags.String("make-target", "fdctl", "TARGET env var passed to make")
+	flags.String("make-target", "fdctl", "TARGET env var passed to make; defaults to firedancer for the firedancer variant")
+	flags.String("variant", string(firedancer.VariantFrankendancer), "variant to package, frankendancer or firedancer")
 }
@@ -35,6 +35,12 @@ func makeCommandGlue(runnerCommand runner.Command) func(cmd *cobra.Command, args
 			return err
 		}
 
+		if w, ok := runnerCommand.(runner.CommandWithWarnings); ok {
+			for _, warning := range w.Warnings() {
+				log.Printf("warning: %s", warning)
+			}
+		}
+
 		p := &runner.Payload{
 			RootPath:    outputDir,
 			DefaultMode: 0640,
@@ -80,9 +86,10 @@ func init() {
 
 		for _, op := range comp.Op {
 			cmd := &cobra.Command{
-				Use:  fmt.Sprintf("%s inputTOML outputDir", op.Action.String()),
-				RunE: makeCommandGlue(op.Creator()),
-				Args: cobra.ExactArgs(2),
+				Use:   fmt.Sprintf("%s inputTOML outputDir", op.Action.String()),
+				Short: fmt.Sprintf("Generate the scripts to %s the %s component", op.Action.String(), comp.Name.String()),
+				RunE:  makeCommandGlue(op.Creator()),
+				Args:  cobra.ExactArgs(2),
 			}
 
 			compCommand.AddCommand(cmd)
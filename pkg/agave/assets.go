@@ -12,5 +12,9 @@ var installScriptTmpl = template.Must(template.ParseFS(assets, "assets/install.s
 var checkValidatorScriptTmpl = template.Must(template.ParseFS(assets, "assets/check-validator.tmpl"))
 
 const (
-	assetsUninstallScript = "assets/uninstall.sh"
+	assetsUninstallScript       = "assets/uninstall.sh"
+	assetsUpdateScript          = "assets/update.sh"
+	assetsValidatorLib          = "assets/validator-lib.sh"
+	assetsFailoverReleaseScript = "assets/failover-release.sh"
+	assetsFailoverAssumeScript  = "assets/failover-assume.sh"
 )
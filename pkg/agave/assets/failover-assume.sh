@@ -0,0 +1,39 @@
+# -*- mode: shell-script -*-
+# shellcheck shell=bash
+
+# shellcheck disable=SC1091
+. ./validator-lib.sh
+
+VALIDATOR_SERVICE=${VALIDATOR_SERVICE}.service
+
+step::00::check-validator() {
+    systemctl is-active --quiet "$VALIDATOR_SERVICE" || log::fatal "the validator isn't running"
+}
+
+step::10::install-tower() {
+    local identity
+
+    identity=$(solana-keygen pubkey validator-keypair.json)
+
+    [[ "$identity" = "$TOWER_IDENTITY" ]] || log::fatal "the tower is for '$TOWER_IDENTITY', not '$identity'"
+
+    svmkit::install-file tower.bin "$(validator::tower-file "$identity")" "$SVMKIT_USER:$SVMKIT_GROUP" 644 || true
+}
+
+step::20::switch-to-staked-identity() {
+    validator::install-keys || true
+    svmkit::install-file authorized-voter-keypair.json "$SVMKIT_HOME/authorized-voter-keypair.json" "$SVMKIT_USER:$SVMKIT_GROUP" 600 || true
+
+    validator::admin set-identity --require-tower "$SVMKIT_HOME/validator-keypair.json"
+    svmkit::status agave-failover-switched "$(solana-keygen pubkey validator-keypair.json | jq -Rc .)"
+
+    validator::admin authorized-voter add "$SVMKIT_HOME/authorized-voter-keypair.json"
+}
+
+step::90::verify-startup() {
+    [[ "${VERIFY_STARTUP:-false}" = "true" ]] || return 0
+
+    validator::verify-startup
+}
+
+# vim:set ft=sh:
@@ -0,0 +1,40 @@
+# -*- mode: shell-script -*-
+# shellcheck shell=bash
+
+# shellcheck disable=SC1091
+. ./validator-lib.sh
+
+VALIDATOR_SERVICE=${VALIDATOR_SERVICE}.service
+
+step::00::check-validator() {
+    systemctl is-active --quiet "$VALIDATOR_SERVICE" || log::fatal "the primary's validator isn't running"
+}
+
+step::10::wait-for-restart-window() {
+    [[ "${WAIT_FOR_RESTART_WINDOW:-false}" = "true" ]] || return 0
+
+    validator::wait-for-restart-window
+}
+
+step::20::switch-to-unstaked-identity() {
+    svmkit::install-file unstaked-identity.json "$SVMKIT_HOME/unstaked-identity.json" "$SVMKIT_USER:$SVMKIT_GROUP" 600 || true
+
+    validator::admin set-identity "$SVMKIT_HOME/unstaked-identity.json"
+    validator::admin authorized-voter remove-all
+
+    # Keep the unstaked identity if the validator is restarted.
+    svmkit::install-file unstaked-identity.json "$SVMKIT_HOME/validator-keypair.json" "$SVMKIT_USER:$SVMKIT_GROUP" 600 || true
+}
+
+step::30::report-tower() {
+    local identity tower
+
+    identity=$(solana-keygen pubkey validator-keypair.json)
+    tower=$(validator::tower-file "$identity")
+
+    svmkit::sudo test -f "$tower" || log::fatal "no tower found at '$tower'; failover needs file tower storage under '$TOWER_PATH'"
+
+    svmkit::status agave-failover-tower "$(svmkit::sudo base64 -w0 "$tower" | jq -Rc --arg identity "$identity" '{identity: $identity, tower: .}')"
+}
+
+# vim:set ft=sh:
@@ -3,6 +3,8 @@
 
 # shellcheck disable=SC1091
 . ./deletion-lib.sh
+# shellcheck disable=SC1091
+. ./logging-lib.sh
 
 VALIDATOR_SERVICE=${VALIDATOR_SERVICE}.service
 
@@ -15,6 +17,10 @@ step::10::stop-services() {
   svmkit::sudo systemctl disable "${VALIDATOR_SERVICE}"
 }
 
+step::20::remove-logging() {
+  logging::uninstall "${VALIDATOR_SERVICE%.service}"
+}
+
 step::80::delete-files() {
     deletion::delete
 }
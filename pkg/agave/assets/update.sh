@@ -0,0 +1,60 @@
+# -*- mode: shell-script -*-
+# shellcheck shell=bash
+
+# shellcheck disable=SC1091
+. ./logging-lib.sh
+# shellcheck disable=SC1091
+. ./validator-lib.sh
+
+VALIDATOR_SERVICE=${VALIDATOR_SERVICE}.service
+
+step::00::wait-for-a-stable-environment() {
+    cloud-init::wait-for-stable-environment
+}
+
+step::10::update-packages() {
+    if validator::install-packages; then
+        RESTART_REASONS+=("packages changed")
+    fi
+}
+
+step::30::update-validator-keys() {
+    if validator::install-keys; then
+        RESTART_REASONS+=("keypairs changed")
+    fi
+}
+
+step::35::update-plugin-config() {
+    if validator::install-plugin-config; then
+        RESTART_REASONS+=("geyser plugin config changed")
+    fi
+}
+
+step::60::setup-solana-cli() {
+    [[ -v SOLANA_CLI_CONFIG_FLAGS ]] || return 0
+
+    solana config set "${SOLANA_CLI_CONFIG_FLAGS[@]}"
+    svmkit::sudo -u "$SVMKIT_USER" -i solana config set "${SOLANA_CLI_CONFIG_FLAGS[@]}"
+}
+
+step::68::configure-logging() {
+    logging::install "${VALIDATOR_SERVICE%.service}"
+}
+
+step::70::update-validator-startup() {
+    if validator::install-startup-files; then
+        RESTART_REASONS+=("flags or startup scripts changed")
+    fi
+}
+
+step::90::restart-validator() {
+    validator::restart
+}
+
+step::95::verify-startup() {
+    [[ "${VERIFY_STARTUP:-false}" = "true" ]] || return 0
+
+    validator::verify-startup
+}
+
+# vim:set ft=sh:
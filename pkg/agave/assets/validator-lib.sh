@@ -0,0 +1,434 @@
+# -*- mode: shell-script -*-
+# shellcheck shell=bash
+
+# Why the validator needs restarting, collected as each step applies
+# its changes.
+RESTART_REASONS=()
+
+# Write the validator's startup scripts and systemd unit into DIR.
+validator::render-startup-files() {
+    local dir=$1
+
+    cat <<EOF >"$dir/stop-validator"
+#!/usr/bin/env bash
+
+$VALIDATOR_ENV exec $VALIDATOR_PROCESS --ledger $LEDGER_PATH exit ${VALIDATOR_EXIT_FLAGS[@]@Q}
+EOF
+
+    cat <<EOF >"$dir/run-validator"
+#!/usr/bin/env bash
+
+$VALIDATOR_ENV exec $VALIDATOR_PROCESS $VALIDATOR_FLAGS
+EOF
+
+    cp check-validator "$dir/check-validator"
+
+    cat <<EOF >"$dir/$VALIDATOR_SERVICE"
+[Unit]
+Description=SVMkit $VALIDATOR_VARIANT validator
+
+[Service]
+Type=exec
+User=$SVMKIT_USER
+Group=$SVMKIT_GROUP
+ExecStart=$SVMKIT_HOME/run-validator
+ExecStartPost=$SVMKIT_HOME/check-validator
+ExecStop=$SVMKIT_HOME/stop-validator
+LimitNOFILE=1000000
+
+[Install]
+WantedBy=default.target
+EOF
+}
+
+# Install the validator's startup scripts and systemd unit.  Returns
+# non-zero if they were all already up to date.
+validator::install-startup-files() {
+    local dir i changed=1
+
+    dir=$(mktemp -d)
+    validator::render-startup-files "$dir"
+
+    for i in run-validator check-validator stop-validator; do
+        svmkit::install-file "$dir/$i" "$SVMKIT_HOME/$i" "$SVMKIT_USER:$SVMKIT_GROUP" 755 && changed=0
+    done
+
+    if svmkit::install-file "$dir/$VALIDATOR_SERVICE" "/etc/systemd/system/$VALIDATOR_SERVICE" root:root 644; then
+        svmkit::sudo systemctl daemon-reload
+        changed=0
+    fi
+
+    rm -rf "$dir"
+
+    return $changed
+}
+
+# Print the file IDENTITY's tower is kept in under TOWER_PATH, as named
+# by agave's file tower storage.
+validator::tower-file() {
+    local identity=$1
+
+    echo "$TOWER_PATH/tower-1_9-$identity.bin"
+}
+
+# Install the validator's keypairs.  Returns non-zero if they were
+# already up to date.
+validator::install-keys() {
+    local i changed=1
+
+    for i in validator-keypair.json vote-account-keypair.json; do
+        svmkit::install-file "$i" "$SVMKIT_HOME/$i" "$SVMKIT_USER:$SVMKIT_GROUP" 600 && changed=0
+    done
+
+    return $changed
+}
+
+# Install the TLS certificates and keys of geyser plugins, generating
+# self-signed ones unless they already exist.  Returns non-zero if
+# nothing changed.  Callers test the result, which turns off errexit,
+# so every failure is checked explicitly.
+validator::install-plugin-tls() {
+    local i cert key changed=1
+
+    for i in "${!GEYSER_TLS_PATHS[@]}"; do
+        cert=${GEYSER_TLS_PATHS[i]}.crt
+        key=${GEYSER_TLS_PATHS[i]}.key
+
+        if [[ "${GEYSER_TLS_SELF_SIGNED[i]}" = "true" ]]; then
+            svmkit::sudo test -f "$cert" -a -f "$key" && continue
+
+            log::info "generating a self-signed certificate '$cert'"
+            svmkit::sudo openssl req -x509 -newkey rsa:2048 -nodes -days 3650 \
+                -subj "/CN=$(hostname)" \
+                -addext "subjectAltName=DNS:$(hostname),DNS:localhost,IP:127.0.0.1" \
+                -keyout "$key" -out "$cert" 2>/dev/null || log::fatal "failed to generate '$cert'"
+            svmkit::sudo chown "$SVMKIT_USER:$SVMKIT_GROUP" "$cert" "$key" || log::fatal "failed to set the owner of '$cert'"
+            svmkit::sudo chmod 644 "$cert" || log::fatal "failed to set the mode of '$cert'"
+            svmkit::sudo chmod 600 "$key" || log::fatal "failed to set the mode of '$key'"
+            changed=0
+            continue
+        fi
+
+        if svmkit::install-file "${GEYSER_TLS_FILES[i]}.crt" "$cert" "$SVMKIT_USER:$SVMKIT_GROUP" 644; then
+            changed=0
+        fi
+
+        if svmkit::install-file "${GEYSER_TLS_FILES[i]}.key" "$key" "$SVMKIT_USER:$SVMKIT_GROUP" 600; then
+            changed=0
+        fi
+    done
+
+    return "$changed"
+}
+
+# Install the geyser plugin configs, and remove those of plugins that
+# have since been dropped.  Returns non-zero if nothing changed.  As
+# with validator::install-plugin-tls, failures are checked explicitly.
+validator::install-plugin-config() {
+    local i f changed=1
+    local -A wanted=()
+
+    [[ -v GEYSER_PLUGIN_DIR ]] || return 1
+
+    svmkit::sudo install -d -m 750 -o "$SVMKIT_USER" -g "$SVMKIT_GROUP" "$GEYSER_PLUGIN_DIR" ||
+        log::fatal "failed to create '$GEYSER_PLUGIN_DIR'"
+
+    # The configs refer to their certificates, so these go first.
+    if validator::install-plugin-tls; then
+        changed=0
+    fi
+
+    for i in "${!GEYSER_CONFIG_FILES[@]}"; do
+        wanted["${GEYSER_CONFIG_PATHS[i]}"]=1
+
+        if [[ "${GEYSER_CONFIG_CHECK[i]}" = "true" ]]; then
+            /usr/bin/config-check -c "${GEYSER_CONFIG_FILES[i]}" ||
+                log::fatal "invalid geyser plugin config '${GEYSER_CONFIG_FILES[i]}'"
+        fi
+
+        if svmkit::install-file "${GEYSER_CONFIG_FILES[i]}" "${GEYSER_CONFIG_PATHS[i]}" "$SVMKIT_USER:$SVMKIT_GROUP" 640; then
+            changed=0
+        fi
+    done
+
+    while IFS= read -r f; do
+        if [[ ! -v wanted["$f"] ]]; then
+            log::info "removing the config of dropped geyser plugin '$f'"
+            svmkit::sudo rm -f "$f" "${f%.json}.crt" "${f%.json}.key" || log::fatal "failed to remove '$f'"
+            changed=0
+        fi
+    done < <(svmkit::sudo find "$GEYSER_PLUGIN_DIR" -maxdepth 1 -name '*.json' 2>/dev/null)
+
+    return "$changed"
+}
+
+# Snapshot the installed package versions, to tell whether an apt run
+# changed anything.
+validator::package-versions() {
+    dpkg-query -W -f='${Package}=${Version}\n' | sort
+}
+
+# Install PACKAGE_LIST.  Returns non-zero if no package changed.
+validator::install-packages() {
+    local before after
+
+    before=$(validator::package-versions)
+
+    svmkit::apt::update
+    svmkit::apt::get --allow-downgrades install "${PACKAGE_LIST[@]}"
+
+    after=$(validator::package-versions)
+
+    [[ "$before" != "$after" ]] || return 1
+
+    log::info "packages changed: $(comm -13 <(echo "$before") <(echo "$after") | tr '\n' ' ')"
+}
+
+# Run one of the validator's admin subcommands against the running
+# validator.
+validator::admin() {
+    svmkit::sudo -u "$SVMKIT_USER" -i "$VALIDATOR_PROCESS" --ledger "$LEDGER_PATH" "$@"
+}
+
+validator::wait-for-restart-window() {
+    log::info "waiting for a restart window"
+
+    svmkit::sudo -u "$SVMKIT_USER" -i \
+        timeout "${RESTART_WINDOW_TIMEOUT:-0}" \
+        "$VALIDATOR_PROCESS" --ledger "$LEDGER_PATH" wait-for-restart-window "${RESTART_WINDOW_FLAGS[@]}" ||
+        log::fatal "no restart window was found"
+}
+
+validator::wait-for-catchup() {
+    log::info "waiting for the validator to catch up"
+
+    svmkit::sudo -u "$SVMKIT_USER" -i \
+        timeout "${CATCHUP_TIMEOUT:-0}" \
+        solana catchup --our-localhost "$RPC_PORT" ||
+        log::fatal "validator did not catch up"
+}
+
+# Restart the validator if RESTART_REASONS calls for it, waiting for a
+# restart window beforehand and for the validator to catch up
+# afterwards if the restart policy asks for it.  Otherwise, just make
+# sure the validator is running.
+validator::restart() {
+    local reason
+
+    if [[ ${#RESTART_REASONS[@]} -eq 0 ]]; then
+        log::info "validator is up to date; not restarting"
+        svmkit::sudo systemctl start "$VALIDATOR_SERVICE"
+        return 0
+    fi
+
+    for reason in "${RESTART_REASONS[@]}"; do
+        log::info "restarting validator: $reason"
+    done
+
+    svmkit::clock::preflight
+
+    if [[ "${WAIT_FOR_RESTART_WINDOW:-false}" = "true" ]] && systemctl is-active --quiet "$VALIDATOR_SERVICE"; then
+        validator::wait-for-restart-window
+    fi
+
+    svmkit::sudo systemctl restart "$VALIDATOR_SERVICE"
+
+    if [[ "${WAIT_FOR_CATCHUP:-false}" = "true" ]]; then
+        validator::wait-for-catchup
+    fi
+}
+
+# Call METHOD on the JSON RPC endpoint at URL.
+validator::rpc() {
+    local url=$1 method=$2 params=${3:-[]}
+
+    curl -s --max-time 10 -X POST -H "Content-Type: application/json" \
+        -d "{\"jsonrpc\":\"2.0\",\"id\":1,\"method\":\"$method\",\"params\":$params}" "$url"
+}
+
+# Check the validator against REFERENCE_RPC_URL, reporting what was
+# found as the agave-startup status.  Returns non-zero unless it is
+# caught up, gossiping and, if VERIFY_VOTING, landing votes.
+validator::check-startup() {
+    local local_url="http://$RPC_BIND_ADDRESS:$RPC_PORT"
+    local identity vote local_slot reference_slot
+    local distance=null caught_up=false gossiping voting=null last_vote=null
+
+    identity=$(solana-keygen pubkey validator-keypair.json)
+
+    local_slot=$(validator::rpc "$local_url" getSlot | jq -r '.result // "null"')
+    reference_slot=$(validator::rpc "$REFERENCE_RPC_URL" getSlot | jq -r '.result // "null"')
+    : "${local_slot:=null}" "${reference_slot:=null}"
+
+    if [[ $local_slot != null && $reference_slot != null ]]; then
+        distance=$((reference_slot - local_slot))
+
+        if ((distance <= MAX_SLOT_DISTANCE)); then
+            caught_up=true
+        fi
+    fi
+
+    gossiping=$(validator::rpc "$REFERENCE_RPC_URL" getClusterNodes | jq -r --arg id "$identity" 'any(.result[]?; .pubkey == $id)')
+    [[ "$gossiping" = "true" ]] || gossiping=false
+
+    if [[ "$VERIFY_VOTING" = "true" ]]; then
+        vote=$(solana-keygen pubkey vote-account-keypair.json)
+        voting=false
+
+        last_vote=$(validator::rpc "$REFERENCE_RPC_URL" getVoteAccounts "[{\"votePubkey\":\"$vote\"}]" | jq -r '.result.current[0].lastVote // "null"')
+        : "${last_vote:=null}"
+
+        if [[ $last_vote != null && $reference_slot != null ]] && ((reference_slot - last_vote <= MAX_SLOT_DISTANCE)); then
+            voting=true
+        fi
+    fi
+
+    svmkit::status agave-startup "$(jq -cn \
+        --argjson localSlot "$local_slot" \
+        --argjson referenceSlot "$reference_slot" \
+        --argjson slotDistance "$distance" \
+        --argjson caughtUp "$caught_up" \
+        --argjson gossiping "$gossiping" \
+        --argjson voting "$voting" \
+        --argjson lastVote "$last_vote" \
+        '$ARGS.named')"
+
+    $caught_up && $gossiping && [[ $voting != false ]]
+}
+
+# Wait up to VERIFY_TIMEOUT seconds for the validator to pass
+# validator::check-startup.
+validator::verify-startup() {
+    local deadline=$((SECONDS + VERIFY_TIMEOUT))
+
+    log::info "verifying the validator against $REFERENCE_RPC_URL"
+
+    until validator::check-startup; do
+        if ((SECONDS >= deadline)); then
+            log::fatal "validator did not catch up, gossip and vote within ${VERIFY_TIMEOUT}s"
+        fi
+
+        sleep 10
+    done
+
+    log::info "validator is caught up"
+}
+
+# Check FILE against SHA256, if one is given.
+validator::verify-sha256() {
+    local file=$1 sha256=${2:-}
+
+    [[ -n "$sha256" ]] || return 0
+
+    echo "$sha256  $file" | svmkit::sudo sha256sum --check --status || log::fatal "checksum mismatch for '$file'"
+}
+
+# Download the snapshot at URL into DIR, keeping the name it is served
+# under.  Returns non-zero if no snapshot could be downloaded.
+validator::download-snapshot() {
+    local url=$1 sha256=$2 dir=$3 partial effective name
+
+    partial="$dir/.svmkit-partial-snapshot"
+
+    log::info "downloading snapshot from '$url'"
+
+    if ! effective=$(svmkit::sudo curl -fsSL --retry 3 -o "$partial" -w '%{url_effective}' "$url"); then
+        svmkit::sudo rm -f "$partial"
+        log::warn "failed to download snapshot from '$url'"
+        return 1
+    fi
+
+    name=$(basename "${effective%%\?*}")
+
+    if [[ "$name" != snapshot-* && "$name" != incremental-snapshot-* ]]; then
+        svmkit::sudo rm -f "$partial"
+        log::warn "'$url' didn't serve a snapshot archive"
+        return 1
+    fi
+
+    validator::verify-sha256 "$partial" "$sha256"
+
+    svmkit::sudo mv "$partial" "$dir/$name"
+    svmkit::sudo chown "$SVMKIT_USER:$SVMKIT_GROUP" "$dir/$name"
+}
+
+# Print the name of the latest KIND ("full" or "incremental")
+# snapshot SNAPSHOT_RPC_HOST serves at RPC_PATH, which redirects to
+# it.  The name must match the slots the host advertises with
+# getHighestSnapshotSlot; the hash in it is what agave checks the
+# snapshot against when it loads it.
+validator::rpc-snapshot-name() {
+    local kind=$1 rpc_path=$2 slots full incremental pattern location name
+
+    slots=$(validator::rpc "http://$SNAPSHOT_RPC_HOST" getHighestSnapshotSlot | jq -c '.result // {}')
+    full=$(jq -r '.full // empty' <<<"$slots")
+    incremental=$(jq -r '.incremental // empty' <<<"$slots")
+
+    if [[ -z "$full" || ("$kind" = incremental && -z "$incremental") ]]; then
+        log::warn "'$SNAPSHOT_RPC_HOST' advertises no $kind snapshot"
+        return 1
+    fi
+
+    case "$kind" in
+    full)
+        pattern="snapshot-$full-*"
+        ;;
+    incremental)
+        pattern="incremental-snapshot-$full-$incremental-*"
+        ;;
+    esac
+
+    location=$(curl -fsS --max-time 10 -o /dev/null -w '%{redirect_url}' "http://$SNAPSHOT_RPC_HOST/$rpc_path") || true
+    name=$(basename "${location%%\?*}")
+
+    # shellcheck disable=SC2053
+    if [[ -z "$location" || "$name" != $pattern ]]; then
+        log::warn "'$SNAPSHOT_RPC_HOST' serves '$name' rather than the $kind snapshot it advertises ($pattern)"
+        return 1
+    fi
+
+    echo "$name"
+}
+
+# Put one KIND of snapshot in place in DIR, from the payload FILE, URL
+# or the RPC host's PATH, whichever is set.  Returns non-zero if it
+# couldn't be seeded.
+validator::seed-snapshot() {
+    local dir=$1 kind=$2 file=$3 url=$4 sha256=$5 rpc_path=$6 name
+
+    if [[ -n "$file" ]]; then
+        validator::verify-sha256 "$file" "$sha256"
+        svmkit::sudo install -m 644 -o "$SVMKIT_USER" -g "$SVMKIT_GROUP" "$file" "$dir/$(basename "$file")"
+    elif [[ -n "$url" ]]; then
+        validator::download-snapshot "$url" "$sha256" "$dir"
+    elif [[ -v SNAPSHOT_RPC_HOST ]]; then
+        name=$(validator::rpc-snapshot-name "$kind" "$rpc_path") || return 1
+        validator::download-snapshot "http://$SNAPSHOT_RPC_HOST/$name" "" "$dir"
+    fi
+}
+
+# Seed the ledger with snapshots, unless it already has a full one.
+validator::seed-snapshots() {
+    local d
+
+    for d in "$SNAPSHOT_FULL_DIR" "$SNAPSHOT_INCREMENTAL_DIR"; do
+        svmkit::sudo install -d -m 755 -o "$SVMKIT_USER" -g "$SVMKIT_GROUP" "$d"
+    done
+
+    if svmkit::sudo find "$SNAPSHOT_FULL_DIR" -maxdepth 1 -name 'snapshot-*' | grep -q .; then
+        log::info "'$SNAPSHOT_FULL_DIR' already has a full snapshot; not seeding"
+        return 0
+    fi
+
+    validator::seed-snapshot "$SNAPSHOT_FULL_DIR" full \
+        "${SNAPSHOT_FULL_FILE:-}" "${SNAPSHOT_FULL_URL:-}" "${SNAPSHOT_FULL_SHA256:-}" \
+        snapshot.tar.bz2 ||
+        log::fatal "failed to seed a full snapshot"
+
+    # The validator can start from the full snapshot alone, so an
+    # incremental one is only nice to have.
+    validator::seed-snapshot "$SNAPSHOT_INCREMENTAL_DIR" incremental \
+        "${SNAPSHOT_INCREMENTAL_FILE:-}" "${SNAPSHOT_INCREMENTAL_URL:-}" "${SNAPSHOT_INCREMENTAL_SHA256:-}" \
+        incremental-snapshot.tar.bz2 ||
+        log::warn "no incremental snapshot was seeded; the validator will catch up from the full snapshot"
+}
@@ -0,0 +1,363 @@
+package agave
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/abklabs/svmkit/pkg/machine/user"
+	"github.com/abklabs/svmkit/pkg/runner"
+	"github.com/abklabs/svmkit/pkg/runner/deployer"
+	"github.com/abklabs/svmkit/pkg/solana"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// FailoverTowerStatusName is the name the primary reports its
+	// tower under when it releases the staked identity.
+	FailoverTowerStatusName = "agave-failover-tower"
+	// FailoverSwitchedStatusName is the name a host reports under once
+	// it has switched to the staked identity.
+	FailoverSwitchedStatusName = "agave-failover-switched"
+
+	defaultFailoverRpcPort = 8899
+)
+
+// FailoverTower is the primary's tower for the staked identity, which
+// the spare needs to carry on voting without risking a lockout
+// violation.
+type FailoverTower struct {
+	Identity string `json:"identity"`
+	Tower    []byte `json:"tower"`
+}
+
+// Failover moves a validator's staked identity from a running primary
+// to a running hot spare.  The primary switches to UnstakedIdentity
+// and drops its authorized voters, and the spare takes on the staked
+// identity with the primary's tower and adds it as an authorized
+// voter.
+//
+// Both hosts keep their new identity across restarts, so the agave
+// resources describing them should have their KeyPairs swapped once
+// the failover is done.
+//
+// If the spare fails before it has switched to the staked identity,
+// the primary is switched back to it with the tower it released, so
+// that something still votes with it.  If the spare switched but then
+// failed its StartupPolicy verification, it keeps the identity.
+//
+// The tower is moved as the file agave keeps it in with file tower
+// storage, its default: tower-1_9-<identity>.bin under TowerPath.
+// Validators using another --tower-storage can't fail over this way.
+type Failover struct {
+	runner.RunnerCommand
+
+	Environment *solana.Environment `pulumi:"environment,optional"`
+	Variant     *Variant            `pulumi:"variant,optional"`
+	ServiceUser *user.ServiceUser   `pulumi:"serviceUser,optional"`
+
+	// KeyPairs are the staked identity and its vote account.
+	KeyPairs         KeyPairs `pulumi:"keyPairs"`
+	UnstakedIdentity string   `pulumi:"unstakedIdentity" provider:"secret"`
+	// AuthorizedVoter is the keypair the spare adds as an authorized
+	// voter once it has the staked identity.  It defaults to the
+	// staked identity.
+	AuthorizedVoter *string `pulumi:"authorizedVoter,optional" provider:"secret"`
+	// TowerPath is the directory both hosts keep their tower in, as
+	// given to agave's --tower.  It defaults to the ledger.
+	TowerPath *string `pulumi:"towerPath,optional"`
+
+	// RestartPolicy's restart window, if any, is waited for on the
+	// primary before the identity is moved.
+	RestartPolicy *RestartPolicy `pulumi:"restartPolicy,optional"`
+	// StartupPolicy's verification, if any, is run on the spare once
+	// it has taken on the identity.
+	StartupPolicy *StartupPolicy `pulumi:"startupPolicy,optional"`
+	// RpcPort is the spare's RPC port, for verifying it.
+	RpcPort *int `pulumi:"rpcPort,optional"`
+}
+
+func (f *Failover) GetVariant() Variant {
+	if f.Variant == nil {
+		return VariantAgave
+	}
+
+	return *f.Variant
+}
+
+func (f *Failover) Release() runner.Command {
+	return &FailoverReleaseCommand{
+		Failover: *f,
+	}
+}
+
+func (f *Failover) Assume(tower *FailoverTower) runner.Command {
+	return &FailoverAssumeCommand{
+		Failover: *f,
+		Tower:    tower,
+	}
+}
+
+// Rollback switches the primary back to the staked identity, with the
+// tower it reported when it released it.
+func (f *Failover) Rollback(tower *FailoverTower) runner.Command {
+	rollback := *f
+	rollback.StartupPolicy = nil
+
+	return rollback.Assume(tower)
+}
+
+func (f *Failover) Check() error {
+	f.SetConfigDefaults()
+
+	if err := f.ServiceUser.Check(); err != nil {
+		return err
+	}
+
+	variant := f.GetVariant()
+
+	if err := variant.Check(); err != nil {
+		return err
+	}
+
+	f.Variant = &variant
+
+	if f.KeyPairs.Identity == "" || f.KeyPairs.VoteAccount == "" {
+		return fmt.Errorf("failover needs the staked identity and vote account keypairs")
+	}
+
+	if f.UnstakedIdentity == "" {
+		return fmt.Errorf("failover needs an unstaked identity for the primary")
+	}
+
+	if f.UnstakedIdentity == f.KeyPairs.Identity {
+		return fmt.Errorf("the unstaked identity must differ from the staked identity")
+	}
+
+	if v := f.AuthorizedVoter; v != nil && *v == "" {
+		return fmt.Errorf("the authorized voter keypair must not be empty")
+	}
+
+	if p := f.TowerPath; p != nil && !path.IsAbs(*p) {
+		return fmt.Errorf("the tower path '%s' must be absolute", *p)
+	}
+
+	if r := f.RestartPolicy; r != nil {
+		if err := r.Check(); err != nil {
+			return err
+		}
+	}
+
+	if s := f.StartupPolicy; s != nil {
+		if err := s.Check(f.Environment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *Failover) Env() *runner.EnvBuilder {
+	b := runner.NewEnvBuilder()
+
+	b.Merge(f.ServiceUser.Env())
+	b.Set("VALIDATOR_PROCESS", f.Variant.ProcessName())
+	b.Set("VALIDATOR_SERVICE", f.Variant.ServiceName())
+	b.Set("LEDGER_PATH", NewPaths(f.ServiceUser).Ledger)
+	b.Set("TOWER_PATH", f.towerPath())
+
+	return b
+}
+
+func (f *Failover) towerPath() string {
+	if f.TowerPath != nil {
+		return *f.TowerPath
+	}
+
+	return NewPaths(f.ServiceUser).Ledger
+}
+
+func (f *Failover) authorizedVoter() string {
+	if f.AuthorizedVoter != nil {
+		return *f.AuthorizedVoter
+	}
+
+	return f.KeyPairs.Identity
+}
+
+// Run performs the failover from primary to spare, returning the
+// spare's startup status if StartupPolicy verified it, even if the
+// verification failed.
+func (f *Failover) Run(ctx context.Context, primary, spare *ssh.Client, logCallback func(string)) (*StartupStatus, error) {
+	run := func(client *ssh.Client, cmd runner.Command) (*runner.Result, error) {
+		if err := cmd.Check(); err != nil {
+			return nil, err
+		}
+
+		handler := &deployer.LoggerHandler{LogCallback: logCallback}
+
+		result, err := runner.NewRunner(client, cmd).RunWithResult(ctx, handler, nil)
+
+		if err != nil {
+			return result, handler.AugmentError(err)
+		}
+
+		return result, nil
+	}
+
+	result, err := run(primary, f.Release())
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to release the identity on the primary: %w", err)
+	}
+
+	var tower FailoverTower
+
+	ok, err := result.Status(FailoverTowerStatusName, &tower)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("the primary didn't report its tower")
+	}
+
+	result, err = run(spare, f.Assume(&tower))
+
+	if err != nil {
+		// Whatever the spare found before it gave up is still
+		// worth returning.
+		status, _ := ParseStartupStatus(result)
+		err = fmt.Errorf("failed to assume the identity on the spare: %w", err)
+
+		if switched, serr := FailoverSwitched(result); serr != nil || switched {
+			return status, errors.Join(err, serr)
+		}
+
+		if _, rerr := run(primary, f.Rollback(&tower)); rerr != nil {
+			return status, fmt.Errorf("%w; switching the primary back to the staked identity also failed, so neither host votes with it: %w", err, rerr)
+		}
+
+		return status, fmt.Errorf("%w; the primary was switched back to the staked identity", err)
+	}
+
+	return ParseStartupStatus(result)
+}
+
+// FailoverSwitched reports whether the host whose result this is
+// switched to the staked identity.  The report is made as soon as
+// agave has taken the identity, so a host is only wrongly taken not
+// to have switched if its connection was lost in that moment.
+func FailoverSwitched(result *runner.Result) (bool, error) {
+	var identity string
+
+	return result.Status(FailoverSwitchedStatusName, &identity)
+}
+
+// FailoverReleaseCommand switches the primary to the unstaked identity
+// and reports its tower for the staked one.
+type FailoverReleaseCommand struct {
+	Failover
+}
+
+func (c *FailoverReleaseCommand) Env() *runner.EnvBuilder {
+	b := c.Failover.Env()
+
+	if r := c.RestartPolicy; r != nil {
+		b.Merge(r.Env())
+	}
+
+	return b
+}
+
+func (c *FailoverReleaseCommand) AddToPayload(p *runner.Payload) error {
+	script, err := assets.Open(assetsFailoverReleaseScript)
+
+	if err != nil {
+		return err
+	}
+
+	p.AddReader(runner.ScriptNameSteps, script)
+
+	lib, err := assets.Open(assetsValidatorLib)
+
+	if err != nil {
+		return err
+	}
+
+	p.AddReader("validator-lib.sh", lib)
+
+	p.AddString("validator-keypair.json", c.KeyPairs.Identity)
+	p.AddString("unstaked-identity.json", c.UnstakedIdentity)
+
+	return nil
+}
+
+// FailoverAssumeCommand installs the primary's tower on the spare and
+// switches it to the staked identity.  Rollback uses it to do the same
+// on the primary.
+type FailoverAssumeCommand struct {
+	Failover
+
+	Tower *FailoverTower
+}
+
+func (c *FailoverAssumeCommand) Check() error {
+	if err := c.Failover.Check(); err != nil {
+		return err
+	}
+
+	if c.Tower == nil || len(c.Tower.Tower) == 0 {
+		return fmt.Errorf("the spare needs the primary's tower")
+	}
+
+	return nil
+}
+
+func (c *FailoverAssumeCommand) Env() *runner.EnvBuilder {
+	b := c.Failover.Env()
+
+	b.Set("TOWER_IDENTITY", c.Tower.Identity)
+
+	if s := c.StartupPolicy; s != nil {
+		rpcPort := defaultFailoverRpcPort
+		if c.RpcPort != nil {
+			rpcPort = *c.RpcPort
+		}
+
+		b.Set("RPC_BIND_ADDRESS", "127.0.0.1")
+		b.SetInt("RPC_PORT", rpcPort)
+		// The spare votes once it has the staked identity, unless
+		// the policy says otherwise.
+		b.Merge(s.VerifyEnv(c.Environment, true))
+	}
+
+	return b
+}
+
+func (c *FailoverAssumeCommand) AddToPayload(p *runner.Payload) error {
+	script, err := assets.Open(assetsFailoverAssumeScript)
+
+	if err != nil {
+		return err
+	}
+
+	p.AddReader(runner.ScriptNameSteps, script)
+
+	lib, err := assets.Open(assetsValidatorLib)
+
+	if err != nil {
+		return err
+	}
+
+	p.AddReader("validator-lib.sh", lib)
+
+	p.AddString("validator-keypair.json", c.KeyPairs.Identity)
+	p.AddString("vote-account-keypair.json", c.KeyPairs.VoteAccount)
+	p.AddString("authorized-voter-keypair.json", c.authorizedVoter())
+	p.NewBuffer(runner.PayloadFile{Path: "tower.bin"}, c.Tower.Tower)
+
+	return nil
+}
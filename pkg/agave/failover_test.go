@@ -0,0 +1,124 @@
+package agave
+
+import (
+	"testing"
+
+	"github.com/abklabs/svmkit/pkg/runner"
+	"github.com/abklabs/svmkit/pkg/runner/runnertest"
+	"github.com/abklabs/svmkit/pkg/solana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailoverCheck(t *testing.T) {
+	f := Failover{KeyPairs: KeyPairs{Identity: "[1]", VoteAccount: "[2]"}}
+	assert.Error(t, f.Check())
+
+	f.UnstakedIdentity = "[1]"
+	assert.Error(t, f.Check())
+
+	f.UnstakedIdentity = "[3]"
+	assert.NoError(t, f.Check())
+
+	towerPath := "tower"
+	f.TowerPath = &towerPath
+	assert.Error(t, f.Check())
+	f.TowerPath = nil
+
+	assert.Error(t, f.Assume(nil).Check())
+}
+
+func TestFailoverTower(t *testing.T) {
+	lines := []string{
+		`svmkit::status agave-failover-tower {"identity":"Staked1111","tower":"dG93ZXI="}`,
+	}
+
+	var tower FailoverTower
+
+	ok, err := runner.ParseStatus(lines, FailoverTowerStatusName, &tower)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	assert.Equal(t, "Staked1111", tower.Identity)
+	assert.Equal(t, []byte("tower"), tower.Tower)
+
+	enabled := true
+	url := "http://localhost:8899"
+
+	f := Failover{
+		Environment:      &solana.Environment{RPCURL: &url},
+		KeyPairs:         KeyPairs{Identity: "[1]", VoteAccount: "[2]"},
+		UnstakedIdentity: "[3]",
+		StartupPolicy:    &StartupPolicy{VerifyStartup: &enabled},
+	}
+
+	cmd := f.Assume(&tower)
+	require.NoError(t, cmd.Check())
+
+	env := cmd.Env().Map()
+	assert.Equal(t, "Staked1111", env["TOWER_IDENTITY"])
+	assert.Equal(t, "8899", env["RPC_PORT"])
+	assert.Equal(t, "true", env["VERIFY_VOTING"])
+	assert.Equal(t, "/home/sol/ledger", env["TOWER_PATH"])
+
+	files := runnertest.PayloadFiles(t, cmd)
+	assert.Equal(t, "tower", files["tower.bin"])
+	assert.Equal(t, "[1]", files["authorized-voter-keypair.json"])
+
+	// The caller's policy decides whether votes are checked for.
+	disabled := false
+	f.StartupPolicy.VerifyVoting = &disabled
+	voter := "[4]"
+	f.AuthorizedVoter = &voter
+	towerPath := "/mnt/tower"
+	f.TowerPath = &towerPath
+
+	cmd = f.Assume(&tower)
+	require.NoError(t, cmd.Check())
+
+	env = cmd.Env().Map()
+	assert.Equal(t, "false", env["VERIFY_VOTING"])
+	assert.Equal(t, "/mnt/tower", env["TOWER_PATH"])
+
+	files = runnertest.PayloadFiles(t, cmd)
+	assert.Equal(t, "[4]", files["authorized-voter-keypair.json"])
+}
+
+func TestFailoverRollback(t *testing.T) {
+	enabled := true
+	url := "http://localhost:8899"
+
+	f := Failover{
+		Environment:      &solana.Environment{RPCURL: &url},
+		KeyPairs:         KeyPairs{Identity: "[1]", VoteAccount: "[2]"},
+		UnstakedIdentity: "[3]",
+		StartupPolicy:    &StartupPolicy{VerifyStartup: &enabled},
+	}
+
+	tower := FailoverTower{Identity: "Staked1111", Tower: []byte("tower")}
+
+	// A spare that gave up before switching leaves the identity to
+	// the primary.
+	switched, err := FailoverSwitched(&runner.Result{})
+	require.NoError(t, err)
+	assert.False(t, switched)
+
+	switched, err = FailoverSwitched(nil)
+	require.NoError(t, err)
+	assert.False(t, switched)
+
+	switched, err = FailoverSwitched(&runner.Result{Lines: []string{
+		`svmkit::status agave-failover-switched "Staked1111"`,
+	}})
+	require.NoError(t, err)
+	assert.True(t, switched)
+
+	// The primary takes back its own tower, and isn't verified.
+	cmd := f.Rollback(&tower)
+	require.NoError(t, cmd.Check())
+
+	env := cmd.Env().Map()
+	assert.Equal(t, "Staked1111", env["TOWER_IDENTITY"])
+	assert.NotContains(t, env, "VERIFY_STARTUP")
+	assert.NotNil(t, f.StartupPolicy)
+}
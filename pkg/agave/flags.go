@@ -70,7 +70,7 @@ type Flags struct {
 	NoIncrementalSnapshots              *bool     `pulumi:"noIncrementalSnapshots,optional"`
 	NoSnapshotFetch                     *bool     `pulumi:"noSnapshotFetch,optional"`
 	NoVoting                            *bool     `pulumi:"noVoting,optional"`
-	NoWaitForVoteToStartLeader          bool      `pulumi:"noWaitForVoteToStartLeader"`
+	NoWaitForVoteToStartLeader          *bool     `pulumi:"noWaitForVoteToStartLeader,optional"`
 	OnlyKnownRPC                        *bool     `pulumi:"onlyKnownRPC,optional"`
 	PrivateRPC                          *bool     `pulumi:"privateRPC,optional"`
 	PublicRpcAddress                    *string   `pulumi:"publicRpcAddress,optional"`
@@ -127,13 +127,19 @@ type Flags struct {
 }
 
 func (f Flags) Args() []string {
+	return f.ArgsWithPaths(NewPaths(nil))
+}
+
+// ArgsWithPaths builds the validator's flags, pointing it at the
+// keypairs and data directories in p.
+func (f Flags) ArgsWithPaths(p Paths) []string {
 	b := runner.FlagBuilder{}
 
 	// Note: These locations are hard coded inside asset-builder.
-	b.Append("identity", identityKeyPairPath)
-	b.Append("vote-account", voteAccountKeyPairPath)
-	b.Append("accounts", accountsPath)
-	b.Append("ledger", ledgerPath)
+	b.Append("identity", p.IdentityKeyPair)
+	b.Append("vote-account", p.VoteAccountKeyPair)
+	b.Append("accounts", p.Accounts)
+	b.Append("ledger", p.Ledger)
 	b.AppendArrayP("account-index", f.AccountIndex)
 	b.AppendArrayP("account-index-exclude-key", f.AccountIndexExcludeKey)
 	b.AppendArrayP("account-index-include-key", f.AccountIndexIncludeKey)
@@ -215,7 +221,7 @@ func (f Flags) Args() []string {
 
 	// Note: This flag is not documented in the Agave validator documentation, but it is
 	// present in the source code.
-	b.AppendBoolP("no-wait-for-vote-to-start-leader", &f.NoWaitForVoteToStartLeader)
+	b.AppendBoolP("no-wait-for-vote-to-start-leader", f.NoWaitForVoteToStartLeader)
 
 	b.AppendBoolP("only-known-rpc", f.OnlyKnownRPC)
 	b.AppendBoolP("private-rpc", f.PrivateRPC)
@@ -0,0 +1,226 @@
+package agave
+
+import (
+	_ "embed"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+//go:embed schema/flags.toml
+var flagSchemaToml []byte
+
+var (
+	versionRegexp          = regexp.MustCompile(`^v?([0-9]+)\.([0-9]+)\.([0-9]+)`)
+	dynamicPortRangeRegexp = regexp.MustCompile(`^([0-9]+)-([0-9]+)$`)
+)
+
+// FlagSpec limits where a flag, or one of its values, may be used.
+type FlagSpec struct {
+	Variants   []Variant `toml:"variants"`
+	Since      string    `toml:"since"`
+	Deprecated string    `toml:"deprecated"`
+	Removed    string    `toml:"removed"`
+}
+
+type FlagSchema struct {
+	Versioned []Variant                      `toml:"versioned"`
+	Until     string                         `toml:"until"`
+	Flags     map[string]FlagSpec            `toml:"flags"`
+	Values    map[string]map[string]FlagSpec `toml:"values"`
+}
+
+func LoadFlagSchema() (*FlagSchema, error) {
+	var s FlagSchema
+
+	if err := toml.Unmarshal(flagSchemaToml, &s); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+type flagVersion [3]int
+
+func parseFlagVersion(s string) (flagVersion, bool) {
+	var v flagVersion
+
+	m := versionRegexp.FindStringSubmatch(s)
+
+	if m == nil {
+		return v, false
+	}
+
+	for i := range v {
+		v[i], _ = strconv.Atoi(m[i+1])
+	}
+
+	return v, true
+}
+
+func (v flagVersion) atLeast(s string) bool {
+	other, ok := parseFlagVersion(s)
+
+	if !ok {
+		return false
+	}
+
+	return slices.Compare(v[:], other[:]) >= 0
+}
+
+// check returns an error if what can't be used with variant at
+// version, or a warning if it is deprecated there.  version is nil if
+// it is unknown or doesn't follow Agave's numbering.
+func (spec FlagSpec) check(what string, variant Variant, version *flagVersion) (string, error) {
+	if len(spec.Variants) != 0 && !slices.Contains(spec.Variants, variant) {
+		return "", fmt.Errorf("%s isn't supported by the %s validator", what, variant)
+	}
+
+	if version == nil {
+		return "", nil
+	}
+
+	if spec.Since != "" && !version.atLeast(spec.Since) {
+		return "", fmt.Errorf("%s needs version %s or later", what, spec.Since)
+	}
+
+	if spec.Removed != "" && version.atLeast(spec.Removed) {
+		return "", fmt.Errorf("%s was removed in version %s", what, spec.Removed)
+	}
+
+	if spec.Deprecated != "" && version.atLeast(spec.Deprecated) {
+		return fmt.Sprintf("%s is deprecated since version %s", what, spec.Deprecated), nil
+	}
+
+	return "", nil
+}
+
+func checkDynamicPortRange(r string) error {
+	m := dynamicPortRangeRegexp.FindStringSubmatch(r)
+
+	if m == nil {
+		return fmt.Errorf("invalid dynamic port range '%s'", r)
+	}
+
+	lo, _ := strconv.Atoi(m[1])
+	hi, _ := strconv.Atoi(m[2])
+
+	if lo < 1 || hi > 65535 || lo >= hi {
+		return fmt.Errorf("invalid dynamic port range '%s'", r)
+	}
+
+	return nil
+}
+
+// covers reports whether the schema lists every flag accepted at
+// version, which is nil for variants and versions it can't place.
+func (s *FlagSchema) covers(version *flagVersion) bool {
+	return version != nil && (s.Until == "" || !version.atLeast(s.Until))
+}
+
+// Validate checks args, as built by Flags.ArgsWithPaths, against the
+// schema for variant at version.  It returns a warning for each
+// deprecated flag or value used, and for each unknown one when the
+// schema doesn't cover variant at version.
+func (s *FlagSchema) Validate(variant Variant, version *string, args []string) ([]string, error) {
+	var v *flagVersion
+
+	if version != nil && slices.Contains(s.Versioned, variant) {
+		if parsed, ok := parseFlagVersion(*version); ok {
+			v = &parsed
+		}
+	}
+
+	covered := s.covers(v)
+
+	var warnings []string
+
+	unknown := func(what string) error {
+		if covered {
+			return fmt.Errorf("unknown %s for the %s validator", what, variant)
+		}
+
+		warnings = append(warnings, fmt.Sprintf("unknown %s for the %s validator, passing it on unchecked", what, variant))
+
+		return nil
+	}
+
+	check := func(spec FlagSpec, what string) error {
+		warning, err := spec.check(what, variant, v)
+
+		if err != nil {
+			return err
+		}
+
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+
+		return nil
+	}
+
+	for i := 0; i < len(args); i++ {
+		if !strings.HasPrefix(args[i], "--") {
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(args[i], "--"), "=")
+
+		if !hasValue && i+1 < len(args) && !strings.HasPrefix(args[i+1], "--") {
+			value = args[i+1]
+			i++
+		}
+
+		flag := "'--" + name + "'"
+
+		spec, ok := s.Flags[name]
+
+		if !ok {
+			if err := unknown("flag " + flag); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		if err := check(spec, flag); err != nil {
+			return nil, err
+		}
+
+		if value == "" {
+			continue
+		}
+
+		if name == "dynamic-port-range" {
+			if err := checkDynamicPortRange(value); err != nil {
+				return nil, err
+			}
+		}
+
+		values, ok := s.Values[name]
+
+		if !ok {
+			continue
+		}
+
+		valueSpec, ok := values[value]
+
+		if !ok {
+			if err := unknown(fmt.Sprintf("value '%s' for %s", value, flag)); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		if err := check(valueSpec, fmt.Sprintf("%s value '%s'", flag, value)); err != nil {
+			return nil, err
+		}
+	}
+
+	return warnings, nil
+}
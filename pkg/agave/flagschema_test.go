@@ -0,0 +1,117 @@
+package agave
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlagSchemaCoversFlags(t *testing.T) {
+	s, err := LoadFlagSchema()
+	require.NoError(t, err)
+
+	// Set every optional flag, so every flag Flags can build is
+	// checked against the schema.
+	var f Flags
+
+	v := reflect.ValueOf(&f).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+
+		if field.Kind() != reflect.Pointer || v.Type().Field(i).Name == "ExtraFlags" {
+			continue
+		}
+
+		elem := reflect.New(field.Type().Elem())
+
+		switch e := elem.Interface().(type) {
+		case *bool:
+			*e = true
+		case *int:
+			*e = 1
+		case *string:
+			*e = "x"
+		case *[]string:
+			*e = []string{"x"}
+		case *[]int:
+			*e = []int{1}
+		default:
+			t.Fatalf("unhandled flag type %s", field.Type())
+		}
+
+		field.Set(elem)
+	}
+
+	for _, a := range f.Args() {
+		if strings.HasPrefix(a, "--") {
+			assert.Contains(t, s.Flags, a[2:])
+		}
+	}
+}
+
+func TestFlagSchemaValidate(t *testing.T) {
+	s, err := LoadFlagSchema()
+	require.NoError(t, err)
+
+	version := func(v string) *string { return &v }
+
+	warnings, err := s.Validate(VariantAgave, version("1.18.26-1"), []string{"--tpu-disable-quic", "--dynamic-port-range", "8000-8020"})
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	warnings, err = s.Validate(VariantAgave, version("2.1.0"), []string{"--tpu-disable-quic"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"'--tpu-disable-quic' is deprecated since version 2.0.0"}, warnings)
+
+	_, err = s.Validate(VariantAgave, version("2.2.14"), []string{"--tpu-disable-quic"})
+	assert.ErrorContains(t, err, "removed in version 2.2.0")
+
+	// Versions aren't checked for variants with their own numbering.
+	_, err = s.Validate(VariantPyth, version("2.2.14"), []string{"--tpu-disable-quic"})
+	assert.NoError(t, err)
+
+	_, err = s.Validate(VariantAgave, version("2.2.14"), []string{"--no-such-flag"})
+	assert.ErrorContains(t, err, "unknown flag")
+
+	// Unknown flags are only warned about where the schema can't
+	// tell whether they exist.
+	for _, tc := range []struct {
+		variant Variant
+		version *string
+	}{
+		{VariantAgave, nil},
+		{VariantAgave, version("2.4.1")},
+		{VariantPyth, version("2.2.14")},
+		{VariantTachyon, nil},
+	} {
+		warnings, err = s.Validate(tc.variant, tc.version, []string{"--no-such-flag", "--block-production-method", "whatever"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"unknown flag '--no-such-flag' for the " + string(tc.variant) + " validator, passing it on unchecked",
+			"unknown value 'whatever' for '--block-production-method' for the " + string(tc.variant) + " validator, passing it on unchecked",
+		}, warnings)
+	}
+
+	_, err = s.Validate(VariantAgave, nil, []string{"--block-engine-url=https://example.com"})
+	assert.ErrorContains(t, err, "isn't supported by the agave validator")
+
+	_, err = s.Validate(VariantJito, nil, []string{"--block-engine-url=https://example.com"})
+	assert.NoError(t, err)
+
+	for _, r := range []string{"8000", "8020-8000", "0-10", "8000-70000"} {
+		_, err = s.Validate(VariantAgave, nil, []string{"--dynamic-port-range", r})
+		assert.Error(t, err, r)
+	}
+
+	_, err = s.Validate(VariantAgave, version("2.2.14"), []string{"--block-production-method", "whatever"})
+	assert.ErrorContains(t, err, "unknown value 'whatever'")
+
+	_, err = s.Validate(VariantAgave, version("2.1.0"), []string{"--block-production-method", "central-scheduler-greedy"})
+	assert.ErrorContains(t, err, "needs version 2.2.0 or later")
+
+	_, err = s.Validate(VariantAgave, version("2.2.0"), []string{"--block-production-method", "central-scheduler-greedy"})
+	assert.NoError(t, err)
+}
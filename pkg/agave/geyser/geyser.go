@@ -1,31 +1,128 @@
 package geyser
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"regexp"
+
+	"github.com/abklabs/svmkit/pkg/runner/deb"
 )
 
 const (
 	PathLibYellowstoneGRPC = "/usr/lib/libyellowstone_grpc_geyser.so"
+	PathLibKafka           = "/usr/lib/libsolana_accountsdb_plugin_kafka.so"
+	PathLibPostgres        = "/usr/lib/libsolana_geyser_plugin_postgres.so"
+)
+
+var (
+	nameRegexp   = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+	pubkeyRegexp = regexp.MustCompile(`^[1-9A-HJ-NP-Za-km-z]{32,44}$`)
 )
 
-// GeyserPlugin provides native support for YellowstoneGRPC geyser plugins through structured configuration.
-// For other geyser plugins, use GenericPluginConfig to specify a JSON config string that must contain
-// a top-level "libpath" field pointing to the plugin's shared library based on where it will be installed
-// on the host machine.
+// GeyserPlugin provides native support for the YellowstoneGRPC, Kafka and Postgres geyser plugins through
+// structured configuration.  For other geyser plugins, use GenericPluginConfig to specify a JSON config string
+// that must contain a top-level "libpath" field pointing to the plugin's shared library based on where it will
+// be installed on the host machine.  Only YellowstoneGRPC is installed by SVMKit; the Kafka, Postgres and generic
+// plugins' libraries must be installed some other way.
 
 type GeyserPlugin struct {
+	// Name distinguishes the plugin from the validator's others, and
+	// names its config file.  It defaults to the kind of plugin,
+	// e.g. "yellowstone-grpc", or "generic".
+	Name                *string          `pulumi:"name,optional"`
 	YellowstoneGRPC     *YellowstoneGRPC `pulumi:"yellowstoneGRPC,optional"`
+	Kafka               *Kafka           `pulumi:"kafka,optional"`
+	Postgres            *Postgres        `pulumi:"postgres,optional"`
 	GenericPluginConfig *string          `pulumi:"genericPluginConfig,optional"`
 }
 
+func (g *GeyserPlugin) GetName() string {
+	switch {
+	case g.Name != nil:
+		return *g.Name
+	case g.YellowstoneGRPC != nil:
+		return "yellowstone-grpc"
+	case g.Kafka != nil:
+		return "kafka"
+	case g.Postgres != nil:
+		return "postgres"
+	default:
+		return "generic"
+	}
+}
+
+// Packages returns the packages the plugin's shared library comes
+// from.  Only YellowstoneGRPC is packaged by SVMKit; the other plugins
+// are expected to be installed some other way.
+func (g *GeyserPlugin) Packages() []deb.Package {
+	if g.YellowstoneGRPC == nil {
+		return nil
+	}
+
+	version := g.YellowstoneGRPC.Version
+	pkgs := []deb.Package{{Name: "svmkit-yellowstone_grpc", Version: &version}}
+
+	if t := g.TLS(); t != nil && t.IsSelfSigned() {
+		pkgs = append(pkgs, deb.Package{Name: "openssl"})
+	}
+
+	return pkgs
+}
+
+// WithTLSPaths returns a copy of the plugin whose TLS configuration
+// points at certPath and keyPath, where SVMKit installs them.
+func (g GeyserPlugin) WithTLSPaths(certPath, keyPath string) *GeyserPlugin {
+	if g.TLS() == nil {
+		return &g
+	}
+
+	y := *g.YellowstoneGRPC
+	conf := *y.Config
+	t := *conf.Grpc.TLSConfig
+
+	t.CertPath, t.KeyPath = certPath, keyPath
+	conf.Grpc.TLSConfig = &t
+	y.Config = &conf
+	g.YellowstoneGRPC = &y
+
+	return &g
+}
+
+// TLS returns the plugin's TLS configuration, if it has one SVMKit
+// can see.
+func (g *GeyserPlugin) TLS() *GrpcConfigGrpcServerTLS {
+	if y := g.YellowstoneGRPC; y != nil && y.Config != nil {
+		return y.Config.Grpc.TLSConfig
+	}
+
+	return nil
+}
+
+// ConfigCheck is whether the plugin's config can be checked on the
+// host with Yellowstone's config-check before it's installed.
+func (g *GeyserPlugin) ConfigCheck() bool {
+	return g.YellowstoneGRPC != nil
+}
+
 func (g *GeyserPlugin) Check() error {
-	if g.YellowstoneGRPC != nil && g.GenericPluginConfig != nil {
-		return fmt.Errorf("only one of YellowstoneGRPC or GenericPluginConfig can be specified")
+	if n := g.Name; n != nil && !nameRegexp.MatchString(*n) {
+		return fmt.Errorf("invalid geyser plugin name '%s'", *n)
 	}
 
-	if g.YellowstoneGRPC == nil && g.GenericPluginConfig == nil {
-		return fmt.Errorf("either YellowstoneGRPC or GenericPluginConfig must be specified")
+	set := 0
+	for _, ok := range []bool{g.YellowstoneGRPC != nil, g.Kafka != nil, g.Postgres != nil, g.GenericPluginConfig != nil} {
+		if ok {
+			set++
+		}
+	}
+
+	if set > 1 {
+		return fmt.Errorf("only one of YellowstoneGRPC, Kafka, Postgres or GenericPluginConfig can be specified")
+	}
+
+	if set == 0 {
+		return fmt.Errorf("one of YellowstoneGRPC, Kafka, Postgres or GenericPluginConfig must be specified")
 	}
 
 	if g.YellowstoneGRPC != nil {
@@ -35,23 +132,121 @@ func (g *GeyserPlugin) Check() error {
 		if g.YellowstoneGRPC.Config != nil && g.YellowstoneGRPC.JSON != nil {
 			return fmt.Errorf("only one of Config or JSON can be specified")
 		}
+
+		if c := g.YellowstoneGRPC.Config; c != nil {
+			if err := c.Check(); err != nil {
+				return err
+			}
+		}
+
+		if j := g.YellowstoneGRPC.JSON; j != nil {
+			if err := ValidateYellowstoneJSON(*j); err != nil {
+				return err
+			}
+		}
+	}
+
+	if c := g.GenericPluginConfig; c != nil {
+		if err := ValidateGenericConfig(*c); err != nil {
+			return err
+		}
+	}
+
+	if t := g.TLS(); t != nil {
+		if err := t.Check(); err != nil {
+			return err
+		}
+	}
+
+	if k := g.Kafka; k != nil {
+		if err := k.Check(); err != nil {
+			return err
+		}
+	}
+
+	if p := g.Postgres; p != nil {
+		if err := p.Check(); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func (g *GeyserPlugin) ToConfigString() (string, error) {
-	if g.YellowstoneGRPC != nil {
-		res, err := g.YellowstoneGRPC.MarshalJSON()
-		if err != nil {
-			return "", err
-		} else {
-			return string(res), nil
+// CheckPlugins checks each of a validator's plugins, and that no two
+// share a name.
+func CheckPlugins(plugins []GeyserPlugin) error {
+	seen := map[string]bool{}
+	versions := map[string]string{}
+
+	for i := range plugins {
+		g := &plugins[i]
+
+		if err := g.Check(); err != nil {
+			return fmt.Errorf("geyser plugin '%s': %w", g.GetName(), err)
 		}
-	} else {
+
+		name := g.GetName()
+
+		if seen[name] {
+			return fmt.Errorf("more than one geyser plugin is named '%s'; give them distinct names", name)
+		}
+
+		seen[name] = true
+
+		for _, pkg := range g.Packages() {
+			version := ""
+			if pkg.Version != nil {
+				version = *pkg.Version
+			}
+
+			if v, ok := versions[pkg.Name]; ok && v != version {
+				return fmt.Errorf("geyser plugins need conflicting versions of '%s'", pkg.Name)
+			}
+
+			versions[pkg.Name] = version
+		}
+	}
+
+	return nil
+}
+
+func (g *GeyserPlugin) ToConfigString() (string, error) {
+	var marshaler json.Marshaler
+
+	switch {
+	case g.YellowstoneGRPC != nil:
+		marshaler = g.YellowstoneGRPC
+	case g.Kafka != nil:
+		marshaler = g.Kafka
+	case g.Postgres != nil:
+		marshaler = g.Postgres
+	default:
 		// Return generic config string as-is
 		return *g.GenericPluginConfig, nil
 	}
+
+	res, err := marshaler.MarshalJSON()
+	if err != nil {
+		return "", err
+	}
+
+	return string(res), nil
+}
+
+// withLibPath adds the libpath field to a plugin's JSON config.
+func withLibPath(config []byte, libpath string) ([]byte, error) {
+	tempMap := make(map[string]any)
+
+	// Unmarshal it into a temp structure so that we can add our own fields
+	if err := json.Unmarshal(config, &tempMap); err != nil {
+		return nil, err
+	}
+
+	tempMap["libpath"] = libpath
+
+	// Convert the combined data to pretty-printed JSON string
+	return json.MarshalIndent(tempMap, "", "  ")
 }
 
 type YellowstoneGRPC struct {
@@ -63,33 +258,17 @@ type YellowstoneGRPC struct {
 }
 
 func (y *YellowstoneGRPC) MarshalJSON() ([]byte, error) {
-	tempMap := make(map[string]any)
-	if y.Config != nil {
-		// Marshal the original config to get its JSON representation
-		origBytes, err := json.Marshal(y.Config)
-		if err != nil {
-			return nil, err
-		}
-
-		// Marshall it into a temp structure so that we can add our own fields
-		if err = json.Unmarshal(origBytes, &tempMap); err != nil {
-			return nil, err
-		}
-	} else {
-		if err := json.Unmarshal([]byte(*y.JSON), &tempMap); err != nil {
-			return nil, err
-		}
+	if y.Config == nil {
+		return withLibPath([]byte(*y.JSON), PathLibYellowstoneGRPC)
 	}
-	// Add the libpath field
-	tempMap["libpath"] = PathLibYellowstoneGRPC
 
-	// Convert the combined data to pretty-printed JSON string
-	configBytes, err := json.MarshalIndent(tempMap, "", "  ")
+	// Marshal the original config to get its JSON representation
+	origBytes, err := json.Marshal(y.Config)
 	if err != nil {
 		return nil, err
 	}
 
-	return configBytes, nil
+	return withLibPath(origBytes, PathLibYellowstoneGRPC)
 }
 
 type Config struct {
@@ -132,9 +311,52 @@ type GrpcConfigGrpc struct {
 	ServerInitialStreamWindowSize     *int32                     `json:"server_initial_stream_window_size,omitempty" pulumi:"serverInitialStreamWindowSize,optional"`
 }
 
+// GrpcConfigGrpcServerTLS either points at a certificate and key
+// already on the host, or has SVMKit put them there: Cert and Key are
+// PEM to upload, and SelfSigned generates a self-signed certificate on
+// the host for test clusters.  CertPath and KeyPath are then set to
+// where they are installed.
 type GrpcConfigGrpcServerTLS struct {
-	CertPath string `json:"cert_path" pulumi:"certPath"`
-	KeyPath  string `json:"key_path" pulumi:"keyPath"`
+	CertPath   string  `json:"cert_path" pulumi:"certPath,optional"`
+	KeyPath    string  `json:"key_path" pulumi:"keyPath,optional"`
+	Cert       *string `json:"-" pulumi:"cert,optional"`
+	Key        *string `json:"-" pulumi:"key,optional" provider:"secret"`
+	SelfSigned *bool   `json:"-" pulumi:"selfSigned,optional"`
+}
+
+// Provisioned is whether SVMKit installs the certificate and key.
+func (t *GrpcConfigGrpcServerTLS) Provisioned() bool {
+	return t.Cert != nil || t.IsSelfSigned()
+}
+
+func (t *GrpcConfigGrpcServerTLS) IsSelfSigned() bool {
+	return t.SelfSigned != nil && *t.SelfSigned
+}
+
+func (t *GrpcConfigGrpcServerTLS) Check() error {
+	if (t.Cert == nil) != (t.Key == nil) {
+		return fmt.Errorf("a TLS certificate needs its key, and the other way around")
+	}
+
+	if t.Cert != nil && t.IsSelfSigned() {
+		return fmt.Errorf("a TLS certificate can't be both supplied and self-signed")
+	}
+
+	if t.Provisioned() {
+		if t.CertPath != "" || t.KeyPath != "" {
+			return fmt.Errorf("TLS certificate and key paths are set by SVMKit when it installs them")
+		}
+	} else if t.CertPath == "" || t.KeyPath == "" {
+		return fmt.Errorf("TLS needs a certificate and key, either as paths on the host, PEM or self-signed")
+	}
+
+	if t.Cert != nil {
+		if _, err := tls.X509KeyPair([]byte(*t.Cert), []byte(*t.Key)); err != nil {
+			return fmt.Errorf("invalid TLS certificate or key: %w", err)
+		}
+	}
+
+	return nil
 }
 
 type GrpcConfigGrpcCompression struct {
@@ -0,0 +1,216 @@
+package geyser
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestCheckPlugins(t *testing.T) {
+	yellowstone := func(version string) GeyserPlugin {
+		return GeyserPlugin{YellowstoneGRPC: &YellowstoneGRPC{JSON: ptr(`{"grpc":{"address":"0.0.0.0:10000"}}`), Version: version}}
+	}
+
+	kafka := GeyserPlugin{Name: ptr("kafka"), GenericPluginConfig: ptr(`{"libpath":"/usr/lib/libsolana_accountsdb_plugin_kafka.so"}`)}
+
+	assert.NoError(t, CheckPlugins(nil))
+	assert.NoError(t, CheckPlugins([]GeyserPlugin{yellowstone("1.0.0"), kafka}))
+
+	second := yellowstone("1.0.0")
+	second.Name = ptr("yellowstone-internal")
+	assert.NoError(t, CheckPlugins([]GeyserPlugin{yellowstone("1.0.0"), second}))
+
+	second.YellowstoneGRPC.Version = "2.0.0"
+	assert.Error(t, CheckPlugins([]GeyserPlugin{yellowstone("1.0.0"), second}))
+
+	assert.Error(t, CheckPlugins([]GeyserPlugin{yellowstone("1.0.0"), yellowstone("1.0.0")}))
+	assert.Error(t, CheckPlugins([]GeyserPlugin{kafka, kafka}))
+	assert.Error(t, CheckPlugins([]GeyserPlugin{{Name: ptr("Bad Name"), GenericPluginConfig: ptr("{}")}}))
+	assert.Error(t, CheckPlugins([]GeyserPlugin{{}}))
+}
+
+func TestGeyserPluginName(t *testing.T) {
+	assert.Equal(t, "yellowstone-grpc", (&GeyserPlugin{YellowstoneGRPC: &YellowstoneGRPC{}}).GetName())
+	assert.Equal(t, "generic", (&GeyserPlugin{GenericPluginConfig: ptr("{}")}).GetName())
+	assert.Equal(t, "kafka", (&GeyserPlugin{Name: ptr("kafka")}).GetName())
+}
+
+func TestKafka(t *testing.T) {
+	k := Kafka{
+		Config: KafkaConfig{
+			Kafka: map[string]string{"bootstrap.servers": "kafka:9092"},
+			Filters: []KafkaFilter{{
+				UpdateAccountTopic: ptr("solana.accounts"),
+				ProgramIgnores:     []string{"Vote111111111111111111111111111111111111111"},
+			}},
+		},
+	}
+
+	g := GeyserPlugin{Kafka: &k}
+	assert.NoError(t, g.Check())
+	assert.Equal(t, "kafka", g.GetName())
+	assert.Empty(t, g.Packages())
+
+	conf, err := g.ToConfigString()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"libpath": "/usr/lib/libsolana_accountsdb_plugin_kafka.so",
+		"kafka": {"bootstrap.servers": "kafka:9092"},
+		"filters": [{"update_account_topic": "solana.accounts", "program_ignores": ["Vote111111111111111111111111111111111111111"]}]
+	}`, conf)
+
+	for _, c := range []KafkaConfig{
+		{Filters: k.Config.Filters},
+		{Kafka: k.Config.Kafka},
+		{Kafka: k.Config.Kafka, Filters: []KafkaFilter{{}}},
+		{Kafka: k.Config.Kafka, Filters: []KafkaFilter{{SlotStatusTopic: ptr("")}}},
+		{Kafka: k.Config.Kafka, Filters: []KafkaFilter{{SlotStatusTopic: ptr("slots"), AccountFilters: []string{"not-a-pubkey"}}}},
+	} {
+		assert.Error(t, c.Check())
+	}
+
+	assert.Error(t, (&GeyserPlugin{Kafka: &k, GenericPluginConfig: ptr("{}")}).Check())
+
+	k.LibPath = ptr("/opt/kafka/libsolana_accountsdb_plugin_kafka.so")
+	conf, err = g.ToConfigString()
+	assert.NoError(t, err)
+	assert.Contains(t, conf, `"libpath": "/opt/kafka/libsolana_accountsdb_plugin_kafka.so"`)
+
+	k.LibPath = ptr("libsolana_accountsdb_plugin_kafka.so")
+	assert.Error(t, g.Check())
+}
+
+func TestPostgres(t *testing.T) {
+	p := Postgres{
+		Config: PostgresConfig{
+			Host:                ptr("db"),
+			User:                ptr("solana"),
+			Port:                ptr(5432),
+			AccountsSelector:    &PostgresAccountsSelector{Owners: []string{"TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"}},
+			TransactionSelector: &PostgresTransactionSelector{Mentions: []string{"all_votes"}},
+		},
+	}
+
+	g := GeyserPlugin{Postgres: &p}
+	assert.NoError(t, g.Check())
+	assert.Equal(t, "postgres", g.GetName())
+	assert.Empty(t, g.Packages())
+
+	conf, err := g.ToConfigString()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"libpath": "/usr/lib/libsolana_geyser_plugin_postgres.so",
+		"host": "db",
+		"user": "solana",
+		"port": 5432,
+		"accounts_selector": {"owners": ["TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"]},
+		"transaction_selector": {"mentions": ["all_votes"]}
+	}`, conf)
+
+	assert.NoError(t, (&PostgresConfig{ConnectionStr: ptr("host=db user=solana password=secret")}).Check())
+
+	for _, c := range []PostgresConfig{
+		{},
+		{Host: ptr("db")},
+		{Host: ptr("db"), User: ptr("solana"), ConnectionStr: ptr("host=db")},
+		{Host: ptr("db"), User: ptr("solana"), Port: ptr(0)},
+		{Host: ptr("db"), User: ptr("solana"), Threads: ptr(0)},
+		{Host: ptr("db"), User: ptr("solana"), UseSSL: ptr(true)},
+		{Host: ptr("db"), User: ptr("solana"), AccountsSelector: &PostgresAccountsSelector{Accounts: []string{"nope"}}},
+		{Host: ptr("db"), User: ptr("solana"), TransactionSelector: &PostgresTransactionSelector{Mentions: []string{"nope"}}},
+	} {
+		assert.Error(t, c.Check())
+	}
+}
+
+func TestYellowstoneLibPath(t *testing.T) {
+	y := YellowstoneGRPC{JSON: ptr(`{"grpc":{"address":"0.0.0.0:10000"}}`)}
+
+	conf, err := y.MarshalJSON()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"libpath":"/usr/lib/libyellowstone_grpc_geyser.so","grpc":{"address":"0.0.0.0:10000"}}`, string(conf))
+}
+
+func testKeyPair(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer})
+
+	return string(cert), string(keyPem)
+}
+
+func TestYellowstoneTLS(t *testing.T) {
+	cert, key := testKeyPair(t)
+	_, otherKey := testKeyPair(t)
+
+	plugin := func(tls GrpcConfigGrpcServerTLS) *GeyserPlugin {
+		return &GeyserPlugin{YellowstoneGRPC: &YellowstoneGRPC{
+			Version: "1.0.0",
+			Config:  &Config{Grpc: GrpcConfigGrpc{Address: "0.0.0.0:10000", TLSConfig: &tls}},
+		}}
+	}
+
+	for _, tls := range []GrpcConfigGrpcServerTLS{
+		{CertPath: "/etc/ssl/grpc.crt", KeyPath: "/etc/ssl/grpc.key"},
+		{Cert: &cert, Key: &key},
+		{SelfSigned: ptr(true)},
+	} {
+		assert.NoError(t, plugin(tls).Check())
+	}
+
+	for _, tls := range []GrpcConfigGrpcServerTLS{
+		{},
+		{CertPath: "/etc/ssl/grpc.crt"},
+		{Cert: &cert},
+		{Cert: &cert, Key: &otherKey},
+		{Cert: ptr("not a certificate"), Key: &key},
+		{Cert: &cert, Key: &key, SelfSigned: ptr(true)},
+		{Cert: &cert, Key: &key, CertPath: "/etc/ssl/grpc.crt", KeyPath: "/etc/ssl/grpc.key"},
+	} {
+		assert.Error(t, plugin(tls).Check())
+	}
+
+	g := plugin(GrpcConfigGrpcServerTLS{SelfSigned: ptr(true)})
+	assert.Equal(t, "openssl", g.Packages()[1].Name)
+
+	conf, err := g.WithTLSPaths("/home/sol/geyser/yellowstone-grpc.crt", "/home/sol/geyser/yellowstone-grpc.key").ToConfigString()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"libpath": "/usr/lib/libyellowstone_grpc_geyser.so",
+		"grpc": {
+			"address": "0.0.0.0:10000",
+			"tls_config": {"cert_path": "/home/sol/geyser/yellowstone-grpc.crt", "key_path": "/home/sol/geyser/yellowstone-grpc.key"}
+		}
+	}`, conf)
+
+	// The copy mustn't touch the original.
+	assert.Empty(t, g.TLS().CertPath)
+}
@@ -0,0 +1,110 @@
+package geyser
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+)
+
+// Kafka configures the Kafka geyser plugin, which publishes account,
+// slot and transaction updates to Kafka topics.  SVMKit doesn't
+// package the plugin, so its shared library must be installed on the
+// host some other way.
+type Kafka struct {
+	Config KafkaConfig `pulumi:"config"`
+	// LibPath is where the plugin's shared library is installed on
+	// the host.  It defaults to PathLibKafka.
+	LibPath *string `pulumi:"libPath,optional"`
+}
+
+func (k *Kafka) Check() error {
+	if l := k.LibPath; l != nil && !path.IsAbs(*l) {
+		return fmt.Errorf("the kafka plugin's library path '%s' must be absolute", *l)
+	}
+
+	return k.Config.Check()
+}
+
+func (k *Kafka) libPath() string {
+	if k.LibPath != nil {
+		return *k.LibPath
+	}
+
+	return PathLibKafka
+}
+
+func (k *Kafka) MarshalJSON() ([]byte, error) {
+	origBytes, err := json.Marshal(k.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	return withLibPath(origBytes, k.libPath())
+}
+
+type KafkaConfig struct {
+	// Kafka is passed to librdkafka as is, and must include
+	// "bootstrap.servers".
+	Kafka             map[string]string `json:"kafka" pulumi:"kafka"`
+	ShutdownTimeoutMs *int              `json:"shutdown_timeout_ms,omitempty" pulumi:"shutdownTimeoutMs,optional"`
+	Filters           []KafkaFilter     `json:"filters" pulumi:"filters"`
+	Prometheus        *string           `json:"prometheus,omitempty" pulumi:"prometheus,optional"`
+}
+
+func (c *KafkaConfig) Check() error {
+	if c.Kafka["bootstrap.servers"] == "" {
+		return fmt.Errorf("the kafka config must set 'bootstrap.servers'")
+	}
+
+	if t := c.ShutdownTimeoutMs; t != nil && *t < 0 {
+		return fmt.Errorf("kafka shutdown timeout can't be negative")
+	}
+
+	if len(c.Filters) == 0 {
+		return fmt.Errorf("the kafka plugin needs at least one filter")
+	}
+
+	for i := range c.Filters {
+		if err := c.Filters[i].Check(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// KafkaFilter selects what is published, and to which topics.
+type KafkaFilter struct {
+	UpdateAccountTopic        *string  `json:"update_account_topic,omitempty" pulumi:"updateAccountTopic,optional"`
+	SlotStatusTopic           *string  `json:"slot_status_topic,omitempty" pulumi:"slotStatusTopic,optional"`
+	TransactionTopic          *string  `json:"transaction_topic,omitempty" pulumi:"transactionTopic,optional"`
+	ProgramIgnores            []string `json:"program_ignores,omitempty" pulumi:"programIgnores,optional"`
+	ProgramFilters            []string `json:"program_filters,omitempty" pulumi:"programFilters,optional"`
+	AccountFilters            []string `json:"account_filters,omitempty" pulumi:"accountFilters,optional"`
+	PublishAllAccounts        *bool    `json:"publish_all_accounts,omitempty" pulumi:"publishAllAccounts,optional"`
+	IncludeVoteTransactions   *bool    `json:"include_vote_transactions,omitempty" pulumi:"includeVoteTransactions,optional"`
+	IncludeFailedTransactions *bool    `json:"include_failed_transactions,omitempty" pulumi:"includeFailedTransactions,optional"`
+	WrapMessages              *bool    `json:"wrap_messages,omitempty" pulumi:"wrapMessages,optional"`
+}
+
+func (f *KafkaFilter) Check() error {
+	if f.UpdateAccountTopic == nil && f.SlotStatusTopic == nil && f.TransactionTopic == nil {
+		return fmt.Errorf("a kafka filter needs at least one topic")
+	}
+
+	for _, t := range []*string{f.UpdateAccountTopic, f.SlotStatusTopic, f.TransactionTopic} {
+		if t != nil && *t == "" {
+			return fmt.Errorf("kafka topics can't be empty")
+		}
+	}
+
+	for _, keys := range [][]string{f.ProgramIgnores, f.ProgramFilters, f.AccountFilters} {
+		for _, k := range keys {
+			if !pubkeyRegexp.MatchString(k) {
+				return fmt.Errorf("invalid kafka filter pubkey '%s'", k)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package geyser
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+)
+
+// Postgres configures the PostgreSQL geyser plugin, which writes
+// accounts, slots and transactions to a database.  SVMKit doesn't
+// package the plugin, so its shared library must be installed on the
+// host some other way.
+type Postgres struct {
+	Config PostgresConfig `pulumi:"config"`
+	// LibPath is where the plugin's shared library is installed on
+	// the host.  It defaults to PathLibPostgres.
+	LibPath *string `pulumi:"libPath,optional"`
+}
+
+func (p *Postgres) Check() error {
+	if l := p.LibPath; l != nil && !path.IsAbs(*l) {
+		return fmt.Errorf("the postgres plugin's library path '%s' must be absolute", *l)
+	}
+
+	return p.Config.Check()
+}
+
+func (p *Postgres) libPath() string {
+	if p.LibPath != nil {
+		return *p.LibPath
+	}
+
+	return PathLibPostgres
+}
+
+func (p *Postgres) MarshalJSON() ([]byte, error) {
+	origBytes, err := json.Marshal(p.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	return withLibPath(origBytes, p.libPath())
+}
+
+// PostgresConfig connects either to Host as User, or with
+// ConnectionStr, e.g. "host=db user=solana password=... port=5432".
+type PostgresConfig struct {
+	Host                       *string                      `json:"host,omitempty" pulumi:"host,optional"`
+	User                       *string                      `json:"user,omitempty" pulumi:"user,optional"`
+	Port                       *int                         `json:"port,omitempty" pulumi:"port,optional"`
+	ConnectionStr              *string                      `json:"connection_str,omitempty" pulumi:"connectionStr,optional" provider:"secret"`
+	Threads                    *int                         `json:"threads,omitempty" pulumi:"threads,optional"`
+	BatchSize                  *int                         `json:"batch_size,omitempty" pulumi:"batchSize,optional"`
+	PanicOnDbErrors            *bool                        `json:"panic_on_db_errors,omitempty" pulumi:"panicOnDbErrors,optional"`
+	StoreAccountHistoricalData *bool                        `json:"store_account_historical_data,omitempty" pulumi:"storeAccountHistoricalData,optional"`
+	IndexTokenOwner            *bool                        `json:"index_token_owner,omitempty" pulumi:"indexTokenOwner,optional"`
+	IndexTokenMint             *bool                        `json:"index_token_mint,omitempty" pulumi:"indexTokenMint,optional"`
+	UseSSL                     *bool                        `json:"use_ssl,omitempty" pulumi:"useSsl,optional"`
+	ServerCA                   *string                      `json:"server_ca,omitempty" pulumi:"serverCa,optional"`
+	ClientCert                 *string                      `json:"client_cert,omitempty" pulumi:"clientCert,optional"`
+	ClientKey                  *string                      `json:"client_key,omitempty" pulumi:"clientKey,optional"`
+	AccountsSelector           *PostgresAccountsSelector    `json:"accounts_selector,omitempty" pulumi:"accountsSelector,optional"`
+	TransactionSelector        *PostgresTransactionSelector `json:"transaction_selector,omitempty" pulumi:"transactionSelector,optional"`
+}
+
+func (c *PostgresConfig) Check() error {
+	if (c.Host == nil) == (c.ConnectionStr == nil) {
+		return fmt.Errorf("the postgres plugin needs exactly one of a host or a connection string")
+	}
+
+	if c.Host != nil && c.User == nil {
+		return fmt.Errorf("the postgres plugin needs a user to connect to its host as")
+	}
+
+	if p := c.Port; p != nil && (*p < 1 || *p > 65535) {
+		return fmt.Errorf("invalid postgres port %d", *p)
+	}
+
+	if t := c.Threads; t != nil && *t < 1 {
+		return fmt.Errorf("postgres threads must be at least 1, not %d", *t)
+	}
+
+	if b := c.BatchSize; b != nil && *b < 1 {
+		return fmt.Errorf("postgres batch size must be at least 1, not %d", *b)
+	}
+
+	if c.UseSSL != nil && *c.UseSSL {
+		if c.ServerCA == nil || c.ClientCert == nil || c.ClientKey == nil {
+			return fmt.Errorf("postgres SSL needs the server CA, client certificate and client key")
+		}
+	}
+
+	if s := c.AccountsSelector; s != nil {
+		if err := checkSelector("accounts", s.Accounts); err != nil {
+			return err
+		}
+
+		if err := checkSelector("owners", s.Owners); err != nil {
+			return err
+		}
+	}
+
+	if s := c.TransactionSelector; s != nil {
+		if err := checkSelector("mentions", s.Mentions); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PostgresAccountsSelector selects the accounts stored, by address or
+// by owner.  "*" selects them all.
+type PostgresAccountsSelector struct {
+	Accounts []string `json:"accounts,omitempty" pulumi:"accounts,optional"`
+	Owners   []string `json:"owners,omitempty" pulumi:"owners,optional"`
+}
+
+// PostgresTransactionSelector selects the transactions stored by the
+// accounts they mention.  "*" selects them all, and "all_votes" all
+// vote transactions.
+type PostgresTransactionSelector struct {
+	Mentions []string `json:"mentions" pulumi:"mentions"`
+}
+
+func checkSelector(what string, keys []string) error {
+	for _, k := range keys {
+		if k == "*" || (what == "mentions" && k == "all_votes") {
+			continue
+		}
+
+		if !pubkeyRegexp.MatchString(k) {
+			return fmt.Errorf("invalid postgres %s selector '%s'", what, k)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,315 @@
+package geyser
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+var (
+	//go:embed schema/yellowstone-grpc.json
+	yellowstoneSchemaJSON string
+	//go:embed schema/generic.json
+	genericSchemaJSON string
+
+	yellowstoneSchema = jsonschema.MustCompileString("yellowstone-grpc.json", yellowstoneSchemaJSON)
+	genericSchema     = jsonschema.MustCompileString("generic.json", genericSchemaJSON)
+
+	// durationRegexp matches the durations Yellowstone parses with
+	// humantime, e.g. "10s" or "1min 30s".
+	durationRegexp = regexp.MustCompile(`^\s*([0-9]+\s*(nsec|ns|usec|us|µs|msec|ms|seconds|second|secs|sec|s|minutes|minute|mins|min|m|hours|hour|hrs|hr|h|days|day|d|weeks|week|w|months|month|M|years|year|y)\s*)+$`)
+)
+
+var compressionValues = []string{"gzip", "zstd"}
+
+// validateJSON checks a plugin's JSON config against schema.
+func validateJSON(what, config string, schema *jsonschema.Schema) error {
+	decoder := json.NewDecoder(bytes.NewBufferString(config))
+	decoder.UseNumber()
+
+	var v any
+
+	if err := decoder.Decode(&v); err != nil {
+		return fmt.Errorf("invalid %s JSON: %w", what, err)
+	}
+
+	if err := schema.Validate(v); err != nil {
+		return fmt.Errorf("invalid %s config: %w", what, err)
+	}
+
+	return nil
+}
+
+// ValidateGenericConfig checks that a generic plugin's config is a
+// JSON object naming the plugin's shared library in "libpath".
+func ValidateGenericConfig(config string) error {
+	return validateJSON("generic geyser plugin", config, genericSchema)
+}
+
+// ValidateYellowstoneJSON checks a Yellowstone config given as JSON,
+// both against its schema and as a Config.
+func ValidateYellowstoneJSON(config string) error {
+	if err := validateJSON("yellowstone gRPC", config, yellowstoneSchema); err != nil {
+		return err
+	}
+
+	var c Config
+
+	if err := json.Unmarshal([]byte(config), &c); err != nil {
+		return fmt.Errorf("invalid yellowstone gRPC JSON: %w", err)
+	}
+
+	return c.Check()
+}
+
+func checkAddress(what, address string) error {
+	host, port, err := net.SplitHostPort(address)
+
+	if err != nil {
+		return fmt.Errorf("invalid %s address '%s': %w", what, address, err)
+	}
+
+	if host != "" && net.ParseIP(host) == nil {
+		return fmt.Errorf("%s address '%s' must be an IP address", what, address)
+	}
+
+	if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+		return fmt.Errorf("invalid %s port in '%s'", what, address)
+	}
+
+	return nil
+}
+
+func checkDuration(what string, d *string) error {
+	if d != nil && !durationRegexp.MatchString(*d) {
+		return fmt.Errorf("invalid %s '%s'; expected a duration like \"10s\" or \"1min 30s\"", what, *d)
+	}
+
+	return nil
+}
+
+func checkPositive(what string, n *int) error {
+	if n != nil && *n < 1 {
+		return fmt.Errorf("%s must be at least 1, not %d", what, *n)
+	}
+
+	return nil
+}
+
+func checkLimit(what string, n *int) error {
+	if n != nil && *n < 0 {
+		return fmt.Errorf("%s can't be negative", what)
+	}
+
+	return nil
+}
+
+func checkPubkeys(what string, keys []string) error {
+	for _, k := range keys {
+		if !pubkeyRegexp.MatchString(k) {
+			return fmt.Errorf("invalid pubkey '%s' in %s", k, what)
+		}
+	}
+
+	return nil
+}
+
+// Check validates the config before it reaches the host, where the
+// plugin's config-check would catch the same mistakes much later.
+func (c *Config) Check() error {
+	if err := checkAddress("gRPC", c.Grpc.Address); err != nil {
+		return err
+	}
+
+	if p := c.Prometheus; p != nil {
+		if err := checkAddress("prometheus", p.Address); err != nil {
+			return err
+		}
+	}
+
+	if t := c.Tokio; t != nil {
+		if err := checkPositive("tokio worker threads", t.WorkerThreads); err != nil {
+			return err
+		}
+
+		if a := t.Affinity; a != nil {
+			for _, cpu := range *a {
+				if cpu < 0 {
+					return fmt.Errorf("invalid tokio affinity CPU %d", cpu)
+				}
+			}
+		}
+	}
+
+	return c.Grpc.Check()
+}
+
+func (g *GrpcConfigGrpc) Check() error {
+	if c := g.Compression; c != nil {
+		for _, v := range append(append([]string{}, c.Accept...), c.Send...) {
+			if !slices.Contains(compressionValues, v) {
+				return fmt.Errorf("invalid gRPC compression '%s'; expected one of %v", v, compressionValues)
+			}
+		}
+	}
+
+	if err := checkDuration("filter names cleanup interval", g.FilterNamesCleanupInterval); err != nil {
+		return err
+	}
+
+	if err := checkDuration("server HTTP/2 keepalive interval", g.ServerHttp2KeepaliveInterval); err != nil {
+		return err
+	}
+
+	if err := checkDuration("server HTTP/2 keepalive timeout", g.ServerHttp2KeepaliveTimeout); err != nil {
+		return err
+	}
+
+	for _, n := range []struct {
+		what string
+		n    *int
+	}{
+		{"max decoding message size", g.MaxDecodingMessageSize},
+		{"snapshot plugin channel capacity", g.SnapshotPluginChannelCapacity},
+		{"snapshot client channel capacity", g.SnapshotClientChannelCapacity},
+		{"channel capacity", g.ChannelCapacity},
+		{"unary concurrency limit", g.UnaryConcurrencyLimit},
+	} {
+		if err := checkPositive(n.what, n.n); err != nil {
+			return err
+		}
+	}
+
+	if err := checkLimit("filter name size limit", g.FilterNameSizeLimit); err != nil {
+		return err
+	}
+
+	if err := checkLimit("filter names size limit", g.FilterNamesSizeLimit); err != nil {
+		return err
+	}
+
+	if r := g.ReplayStoredSlots; r != nil && *r < 0 {
+		return fmt.Errorf("replay stored slots can't be negative")
+	}
+
+	if l := g.FilterLimits; l != nil {
+		return l.Check()
+	}
+
+	return nil
+}
+
+func isZero(n *int) bool {
+	return n != nil && *n == 0
+}
+
+func isFalse(b *bool) bool {
+	return b != nil && !*b
+}
+
+// unsatisfiable returns an error if a kind of filter is allowed (its
+// max isn't zero), yet its limits reject every filter of that kind.
+func unsatisfiable(what string, max *int, rejectsAll bool) error {
+	if rejectsAll && !isZero(max) {
+		return fmt.Errorf("the %s limits reject every filter; set its max to 0 to disable it instead", what)
+	}
+
+	return nil
+}
+
+// Check makes sure the filter limits are consistent: no limit is
+// negative, rejected keys are valid, and no kind of filter is allowed
+// by its max while its other limits reject every such filter.
+func (l *GrpcConfigFilterLimits) Check() error {
+	if a := l.Accounts; a != nil {
+		for _, n := range []*int{a.Max, a.AccountMax, a.OwnerMax, a.DataSliceMax} {
+			if err := checkLimit("accounts filter limits", n); err != nil {
+				return err
+			}
+		}
+
+		if err := checkPubkeys("the accounts filter's account reject", a.AccountReject); err != nil {
+			return err
+		}
+
+		if err := checkPubkeys("the accounts filter's owner reject", a.OwnerReject); err != nil {
+			return err
+		}
+
+		// Without "any", a filter must name accounts or owners.
+		if err := unsatisfiable("accounts filter", a.Max, isFalse(a.Any) && isZero(a.AccountMax) && isZero(a.OwnerMax)); err != nil {
+			return err
+		}
+	}
+
+	for _, t := range []struct {
+		what   string
+		limits *GrpcConfigFilterLimitsTransactions
+	}{
+		{"transactions filter", l.Transactions},
+		{"transactions status filter", l.TransactionsStatus},
+	} {
+		limits := t.limits
+
+		if limits == nil {
+			continue
+		}
+
+		for _, n := range []*int{limits.Max, limits.AccountIncludeMax, limits.AccountExcludeMax, limits.AccountRequiredMax} {
+			if err := checkLimit(t.what+" limits", n); err != nil {
+				return err
+			}
+		}
+
+		if err := checkPubkeys("the "+t.what+"'s account include reject", limits.AccountIncludeReject); err != nil {
+			return err
+		}
+
+		if err := unsatisfiable(t.what, limits.Max, isFalse(limits.Any) && isZero(limits.AccountIncludeMax) && isZero(limits.AccountExcludeMax) && isZero(limits.AccountRequiredMax)); err != nil {
+			return err
+		}
+	}
+
+	if b := l.Blocks; b != nil {
+		for _, n := range []*int{b.Max, b.AccountIncludeMax} {
+			if err := checkLimit("blocks filter limits", n); err != nil {
+				return err
+			}
+		}
+
+		if err := checkPubkeys("the blocks filter's account include reject", b.AccountIncludeReject); err != nil {
+			return err
+		}
+
+		if err := unsatisfiable("blocks filter", b.Max, isFalse(b.AccountIncludeAny) && isZero(b.AccountIncludeMax)); err != nil {
+			return err
+		}
+	}
+
+	if s := l.Slots; s != nil {
+		if err := checkLimit("slots filter max", s.Max); err != nil {
+			return err
+		}
+	}
+
+	if m := l.BlocksMeta; m != nil {
+		if err := checkLimit("blocks meta filter max", m.Max); err != nil {
+			return err
+		}
+	}
+
+	if e := l.Entries; e != nil {
+		if err := checkLimit("entries filter max", e.Max); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package geyser
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPubkey = "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"
+
+func TestValidateGenericConfig(t *testing.T) {
+	assert.NoError(t, ValidateGenericConfig(`{"libpath":"/usr/lib/libplugin.so","anything":{"else":1}}`))
+
+	for _, c := range []string{
+		``,
+		`{}`,
+		`[]`,
+		`{"libpath":1}`,
+		`{"libpath":"libplugin.so"}`,
+		`{"libpath":"/usr/lib/libplugin"}`,
+		`{"libpath":"/usr/lib/libplugin.so"`,
+	} {
+		assert.Error(t, ValidateGenericConfig(c), c)
+	}
+
+	assert.Error(t, (&GeyserPlugin{GenericPluginConfig: ptr(`{}`)}).Check())
+}
+
+func TestValidateYellowstoneJSON(t *testing.T) {
+	for _, c := range []string{
+		`{"grpc":{"address":"0.0.0.0:10000"}}`,
+		`{"libpath":"/usr/lib/libyellowstone_grpc_geyser.so","grpc":{"address":"[::]:10000"}}`,
+		`{"grpc":{"address":"0.0.0.0:10000","compression":{"accept":["gzip","zstd"]},"server_http2_keepalive_interval":"1min 30s"}}`,
+		`{"grpc":{"address":"0.0.0.0:10000"},"prometheus":{"address":"127.0.0.1:8999"}}`,
+	} {
+		assert.NoError(t, ValidateYellowstoneJSON(c), c)
+	}
+
+	for _, c := range []string{
+		`{}`,
+		`{"grpc":{}}`,
+		`{"grpc":{"address":"0.0.0.0:10000","bogus":true}}`,
+		`{"grpc":{"address":"localhost:10000"}}`,
+		`{"grpc":{"address":"0.0.0.0:0"}}`,
+		`{"grpc":{"address":"0.0.0.0:10000","compression":{"send":["brotli"]}}}`,
+		`{"grpc":{"address":"0.0.0.0:10000","server_http2_keepalive_timeout":"soon"}}`,
+		`{"grpc":{"address":"0.0.0.0:10000","channel_capacity":"lots"}}`,
+		`{"grpc":{"address":"0.0.0.0:10000"},"prometheus":{"address":"8999"}}`,
+	} {
+		assert.Error(t, ValidateYellowstoneJSON(c), c)
+	}
+
+	assert.Error(t, (&GeyserPlugin{YellowstoneGRPC: &YellowstoneGRPC{JSON: ptr(`{"grpc":{"address":":10000","compression":{"accept":["lz4"]}}}`)}}).Check())
+}
+
+func TestConfigCheck(t *testing.T) {
+	config := func() *Config {
+		return &Config{
+			Tokio: &GrpcConfigTokio{WorkerThreads: ptr(4), Affinity: &[]int{0, 1, 2, 3}},
+			Grpc: GrpcConfigGrpc{
+				Address:                      "0.0.0.0:10000",
+				Compression:                  &GrpcConfigGrpcCompression{Accept: []string{"gzip"}, Send: []string{"zstd"}},
+				ChannelCapacity:              ptr(100000),
+				ServerHttp2KeepaliveInterval: ptr("10s"),
+				FilterLimits: &GrpcConfigFilterLimits{
+					Accounts:     &GrpcConfigFilterLimitsAccounts{Max: ptr(1), Any: ptr(false), AccountMax: ptr(10), AccountReject: []string{testPubkey}},
+					Transactions: &GrpcConfigFilterLimitsTransactions{Max: ptr(0), Any: ptr(false), AccountIncludeMax: ptr(0), AccountExcludeMax: ptr(0), AccountRequiredMax: ptr(0)},
+					Blocks:       &GrpcConfigFilterLimitsBlocks{Max: ptr(1), AccountIncludeAny: ptr(true), AccountIncludeMax: ptr(0)},
+				},
+			},
+			Prometheus: &GrpcConfigPrometheus{Address: "0.0.0.0:8999"},
+		}
+	}
+
+	c := config()
+	require.NoError(t, c.Check())
+	assert.NoError(t, (&GeyserPlugin{YellowstoneGRPC: &YellowstoneGRPC{Config: c}}).Check())
+
+	// Whatever a valid Config marshals to must pass the schema too.
+	j, err := json.Marshal(c)
+	require.NoError(t, err)
+	assert.NoError(t, ValidateYellowstoneJSON(string(j)))
+
+	for _, mutate := range []func(c *Config){
+		func(c *Config) { c.Grpc.Address = "10000" },
+		func(c *Config) { c.Grpc.Address = "grpc.example.com:10000" },
+		func(c *Config) { c.Grpc.Address = "0.0.0.0:65536" },
+		func(c *Config) { c.Prometheus.Address = "0.0.0.0" },
+		func(c *Config) { c.Tokio.WorkerThreads = ptr(0) },
+		func(c *Config) { c.Tokio.Affinity = &[]int{-1} },
+		func(c *Config) { c.Grpc.Compression.Send = []string{"deflate"} },
+		func(c *Config) { c.Grpc.ServerHttp2KeepaliveInterval = ptr("10") },
+		func(c *Config) { c.Grpc.FilterNamesCleanupInterval = ptr("") },
+		func(c *Config) { c.Grpc.ChannelCapacity = ptr(0) },
+		func(c *Config) { c.Grpc.FilterNameSizeLimit = ptr(-1) },
+		func(c *Config) { c.Grpc.FilterLimits.Accounts.AccountMax = ptr(-1) },
+		func(c *Config) { c.Grpc.FilterLimits.Accounts.OwnerReject = []string{"nope"} },
+		func(c *Config) {
+			c.Grpc.FilterLimits.Accounts.AccountMax = ptr(0)
+			c.Grpc.FilterLimits.Accounts.OwnerMax = ptr(0)
+		},
+		func(c *Config) { c.Grpc.FilterLimits.Transactions.Max = ptr(5) },
+		func(c *Config) { c.Grpc.FilterLimits.Blocks.AccountIncludeAny = ptr(false) },
+		func(c *Config) { c.Grpc.FilterLimits.Entries = &GrpcConfigFilterLimitsEntries{Max: ptr(-1)} },
+	} {
+		c := config()
+		mutate(c)
+
+		assert.Error(t, c.Check())
+		assert.Error(t, (&GeyserPlugin{YellowstoneGRPC: &YellowstoneGRPC{Config: c}}).Check())
+	}
+}
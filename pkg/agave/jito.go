@@ -0,0 +1,76 @@
+package agave
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+
+	"github.com/abklabs/svmkit/pkg/runner"
+)
+
+var pubkeyRegexp = regexp.MustCompile(`^[1-9A-HJ-NP-Za-km-z]{32,44}$`)
+
+// Jito configures the jito variant's block engine, relayer and tip
+// programs.  It may only be set for the jito variant.
+type Jito struct {
+	BlockEngineURL               *string `pulumi:"blockEngineURL,optional"`
+	RelayerURL                   *string `pulumi:"relayerURL,optional"`
+	ShredReceiverAddress         *string `pulumi:"shredReceiverAddress,optional"`
+	TipPaymentProgramPubkey      string  `pulumi:"tipPaymentProgramPubkey"`
+	TipDistributionProgramPubkey string  `pulumi:"tipDistributionProgramPubkey"`
+	MerkleRootUploadAuthority    string  `pulumi:"merkleRootUploadAuthority"`
+	// CommissionBps is the commission taken on MEV tips, in basis
+	// points.
+	CommissionBps int `pulumi:"commissionBps"`
+}
+
+func (j *Jito) Check() error {
+	for _, u := range []*string{j.BlockEngineURL, j.RelayerURL} {
+		if u == nil {
+			continue
+		}
+
+		parsed, err := url.ParseRequestURI(*u)
+
+		if err != nil {
+			return fmt.Errorf("invalid jito URL '%s': %w", *u, err)
+		}
+
+		if parsed.Scheme != "http" && parsed.Scheme != "https" {
+			return fmt.Errorf("jito URL '%s' must be http or https", *u)
+		}
+	}
+
+	if a := j.ShredReceiverAddress; a != nil {
+		if _, _, err := net.SplitHostPort(*a); err != nil {
+			return fmt.Errorf("invalid shred receiver address '%s': %w", *a, err)
+		}
+	}
+
+	for _, k := range []string{j.TipPaymentProgramPubkey, j.TipDistributionProgramPubkey, j.MerkleRootUploadAuthority} {
+		if !pubkeyRegexp.MatchString(k) {
+			return fmt.Errorf("invalid jito pubkey '%s'", k)
+		}
+	}
+
+	if j.CommissionBps < 0 || j.CommissionBps > 10000 {
+		return fmt.Errorf("jito commission must be between 0 and 10000 basis points, not %d", j.CommissionBps)
+	}
+
+	return nil
+}
+
+func (j *Jito) Flags() *runner.FlagBuilder {
+	f := &runner.FlagBuilder{}
+
+	f.AppendP("block-engine-url", j.BlockEngineURL)
+	f.AppendP("relayer-url", j.RelayerURL)
+	f.AppendP("shred-receiver-address", j.ShredReceiverAddress)
+	f.Append("tip-payment-program-pubkey", j.TipPaymentProgramPubkey)
+	f.Append("tip-distribution-program-pubkey", j.TipDistributionProgramPubkey)
+	f.Append("merkle-root-upload-authority", j.MerkleRootUploadAuthority)
+	f.AppendIntP("commission-bps", &j.CommissionBps)
+
+	return f
+}
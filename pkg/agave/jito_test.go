@@ -0,0 +1,47 @@
+package agave
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJito(t *testing.T) {
+	blockEngine := "https://mainnet.block-engine.jito.wtf"
+	shredReceiver := "74.118.140.240:1002"
+
+	j := &Jito{
+		BlockEngineURL:               &blockEngine,
+		ShredReceiverAddress:         &shredReceiver,
+		TipPaymentProgramPubkey:      "T1pyyaTNZsKv2WcRAB8oVnk93mLJw2XzjtVYqCsaHqt",
+		TipDistributionProgramPubkey: "4R3gSG8BpU4t19KYj8CfnbtRpnT8gtk4dvTHxVRwc2r7",
+		MerkleRootUploadAuthority:    "GZctHpWXmsZC1YHACTGGcHhYxjdRqQvTpYkb9LMvxDib",
+		CommissionBps:                800,
+	}
+
+	require.NoError(t, j.Check())
+
+	assert.Equal(t, []string{
+		"--block-engine-url", blockEngine,
+		"--shred-receiver-address", shredReceiver,
+		"--tip-payment-program-pubkey", "T1pyyaTNZsKv2WcRAB8oVnk93mLJw2XzjtVYqCsaHqt",
+		"--tip-distribution-program-pubkey", "4R3gSG8BpU4t19KYj8CfnbtRpnT8gtk4dvTHxVRwc2r7",
+		"--merkle-root-upload-authority", "GZctHpWXmsZC1YHACTGGcHhYxjdRqQvTpYkb9LMvxDib",
+		"--commission-bps", "800",
+	}, j.Flags().Args())
+
+	a := Agave{KeyPairs: KeyPairs{Identity: "[1]", VoteAccount: "[2]"}, Jito: j}
+	assert.ErrorContains(t, a.Install().Check(), "only supported by the jito variant")
+
+	variant := VariantJito
+	a.Variant = &variant
+	assert.NoError(t, a.Install().Check())
+
+	j.CommissionBps = 10001
+	assert.Error(t, j.Check())
+
+	j.CommissionBps = 800
+	j.TipPaymentProgramPubkey = "not-a-pubkey"
+	assert.Error(t, j.Check())
+}
@@ -0,0 +1,29 @@
+package agave
+
+import (
+	"github.com/abklabs/svmkit/pkg/machine/user"
+)
+
+// Paths are where the validator's files live on the host.  They're all
+// relative to the service user's home directory.  GeyserConfig is the
+// config of GeyserPlugin, and GeyserPlugins the directory holding a
+// config for each of GeyserPlugins.
+type Paths struct {
+	Accounts           string
+	Ledger             string
+	IdentityKeyPair    string
+	VoteAccountKeyPair string
+	GeyserConfig       string
+	GeyserPlugins      string
+}
+
+func NewPaths(u *user.ServiceUser) Paths {
+	return Paths{
+		Accounts:           u.Path("accounts"),
+		Ledger:             u.Path("ledger"),
+		IdentityKeyPair:    u.Path("validator-keypair.json"),
+		VoteAccountKeyPair: u.Path("vote-account-keypair.json"),
+		GeyserConfig:       u.Path("geyser-config.json"),
+		GeyserPlugins:      u.Path("geyser"),
+	}
+}
@@ -1,11 +1,90 @@
 package agave
 
 import (
+	"fmt"
+
 	"github.com/abklabs/svmkit/pkg/runner"
+	"github.com/abklabs/svmkit/pkg/solana"
+)
+
+const (
+	defaultMaxSlotDistance = 150
+	defaultVerifyTimeout   = 600
 )
 
 type StartupPolicy struct {
 	WaitForRPCHealth *bool `pulumi:"waitForRPCHealth,optional"`
+	// MaxClockOffset is the largest clock offset from NTP, in
+	// seconds, the validator may be started with.
+	MaxClockOffset *float64 `pulumi:"maxClockOffset,optional"`
+
+	// VerifyStartup checks, once the validator is started, that it
+	// is within MaxSlotDistance of the environment's RPC endpoint,
+	// is gossiping and, unless it was started with noVoting, is
+	// landing votes.  The outcome is reported as a StartupStatus.
+	VerifyStartup   *bool `pulumi:"verifyStartup,optional"`
+	MaxSlotDistance *int  `pulumi:"maxSlotDistance,optional"`
+	// VerifyVoting is whether the verification expects votes to
+	// land.  It defaults to whether the validator should be voting.
+	VerifyVoting *bool `pulumi:"verifyVoting,optional"`
+	// VerifyTimeout is how long, in seconds, the validator has to
+	// pass the checks.
+	VerifyTimeout *int `pulumi:"verifyTimeout,optional"`
+}
+
+func (s *StartupPolicy) verify() bool {
+	return s.VerifyStartup != nil && *s.VerifyStartup
+}
+
+func (s *StartupPolicy) Check(env *solana.Environment) error {
+	if s.MaxClockOffset != nil && *s.MaxClockOffset <= 0 {
+		return fmt.Errorf("maxClockOffset must be positive")
+	}
+
+	for _, v := range []*int{s.MaxSlotDistance, s.VerifyTimeout} {
+		if v != nil && *v < 0 {
+			return fmt.Errorf("startup policy limits must not be negative")
+		}
+	}
+
+	if s.verify() && (env == nil || env.RPCURL == nil) {
+		return fmt.Errorf("verifying startup needs an environment RPC URL to compare against")
+	}
+
+	return nil
+}
+
+// VerifyEnv returns the environment for the startup verification.
+// voting is whether the validator should be voting, which VerifyVoting
+// overrides.
+func (s *StartupPolicy) VerifyEnv(env *solana.Environment, voting bool) *runner.EnvBuilder {
+	e := runner.NewEnvBuilder()
+
+	if !s.verify() {
+		return e
+	}
+
+	maxSlotDistance := defaultMaxSlotDistance
+	if s.MaxSlotDistance != nil {
+		maxSlotDistance = *s.MaxSlotDistance
+	}
+
+	timeout := defaultVerifyTimeout
+	if s.VerifyTimeout != nil {
+		timeout = *s.VerifyTimeout
+	}
+
+	if s.VerifyVoting != nil {
+		voting = *s.VerifyVoting
+	}
+
+	e.SetBool("VERIFY_STARTUP", true)
+	e.SetBool("VERIFY_VOTING", voting)
+	e.SetInt("MAX_SLOT_DISTANCE", maxSlotDistance)
+	e.SetInt("VERIFY_TIMEOUT", timeout)
+	e.SetP("REFERENCE_RPC_URL", env.RPCURL)
+
+	return e
 }
 
 type ShutdownPolicy struct {
@@ -20,6 +99,16 @@ func (s *ShutdownPolicy) Flags() *runner.FlagBuilder {
 	f := &runner.FlagBuilder{}
 
 	f.AppendBoolP("force", s.Force)
+	f.AppendRaw(s.RestartWindowFlags().Args()...)
+
+	return f
+}
+
+// RestartWindowFlags returns the flags that pick the restart window,
+// which wait-for-restart-window takes as well as exit.
+func (s *ShutdownPolicy) RestartWindowFlags() *runner.FlagBuilder {
+	f := &runner.FlagBuilder{}
+
 	f.AppendBoolP("skip-health-check", s.SkipHealthCheck)
 	f.AppendBoolP("skip-new-snapshot-check", s.SkipNewSnapshotCheck)
 	f.AppendIntP("max-delinquent-stake", s.MaxDelinquentStake)
@@ -27,3 +116,63 @@ func (s *ShutdownPolicy) Flags() *runner.FlagBuilder {
 
 	return f
 }
+
+// RestartPolicy controls how a running validator is restarted when its
+// packages, flags, keypairs or plugin config change.  Without one the
+// validator is simply stopped and started again.
+type RestartPolicy struct {
+	// ShutdownPolicy picks the restart window, as it does for exit.
+	// Force doesn't apply to waiting for one.
+	ShutdownPolicy
+
+	// WaitForRestartWindow holds the restart until the validator has
+	// no leader slots coming up, using wait-for-restart-window.
+	WaitForRestartWindow *bool `pulumi:"waitForRestartWindow,optional"`
+	// RestartWindowTimeout is how long, in seconds, to wait for a
+	// restart window before failing.
+	RestartWindowTimeout *int `pulumi:"restartWindowTimeout,optional"`
+
+	// WaitForCatchup waits, after the restart, until the validator
+	// has caught up with the cluster.
+	WaitForCatchup *bool `pulumi:"waitForCatchup,optional"`
+	// CatchupTimeout is how long, in seconds, to wait for the
+	// validator to catch up before failing.
+	CatchupTimeout *int `pulumi:"catchupTimeout,optional"`
+}
+
+func (r *RestartPolicy) Check() error {
+	if r.Force != nil {
+		return fmt.Errorf("force doesn't apply to waiting for a restart window")
+	}
+
+	for _, v := range []*int{r.MinIdleTime, r.RestartWindowTimeout, r.CatchupTimeout} {
+		if v != nil && *v < 0 {
+			return fmt.Errorf("restart policy times must not be negative")
+		}
+	}
+
+	if v := r.MaxDelinquentStake; v != nil && (*v < 0 || *v > 100) {
+		return fmt.Errorf("maxDelinquentStake must be a percentage, not %d", *v)
+	}
+
+	return nil
+}
+
+func (r *RestartPolicy) Env() *runner.EnvBuilder {
+	e := runner.NewEnvBuilder()
+
+	e.SetBool("SAFE_RESTART", true)
+
+	if r.WaitForRestartWindow != nil && *r.WaitForRestartWindow {
+		e.SetBool("WAIT_FOR_RESTART_WINDOW", true)
+		e.SetArray("RESTART_WINDOW_FLAGS", r.RestartWindowFlags().Args())
+		e.SetIntP("RESTART_WINDOW_TIMEOUT", r.RestartWindowTimeout)
+	}
+
+	if r.WaitForCatchup != nil && *r.WaitForCatchup {
+		e.SetBool("WAIT_FOR_CATCHUP", true)
+		e.SetIntP("CATCHUP_TIMEOUT", r.CatchupTimeout)
+	}
+
+	return e
+}
@@ -0,0 +1,73 @@
+package agave
+
+import (
+	"testing"
+
+	"github.com/abklabs/svmkit/pkg/solana"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestartPolicy(t *testing.T) {
+	enabled := true
+	idle := 10
+	stake := 5
+	timeout := 600
+
+	r := RestartPolicy{
+		ShutdownPolicy: ShutdownPolicy{
+			MinIdleTime:        &idle,
+			MaxDelinquentStake: &stake,
+		},
+		WaitForRestartWindow: &enabled,
+		RestartWindowTimeout: &timeout,
+		WaitForCatchup:       &enabled,
+	}
+
+	assert.NoError(t, r.Check())
+	assert.Equal(t, []string{"--max-delinquent-stake", "5", "--min-idle-time", "10"}, r.RestartWindowFlags().Args())
+
+	env := r.Env().Map()
+
+	assert.Equal(t, "true", env["WAIT_FOR_RESTART_WINDOW"])
+	assert.Equal(t, "(--max-delinquent-stake 5 --min-idle-time 10)", env["RESTART_WINDOW_FLAGS"])
+	assert.Equal(t, "600", env["RESTART_WINDOW_TIMEOUT"])
+	assert.Equal(t, "true", env["WAIT_FOR_CATCHUP"])
+	assert.NotContains(t, env, "CATCHUP_TIMEOUT")
+
+	stake = 101
+	assert.Error(t, r.Check())
+
+	stake = 5
+	timeout = -1
+	assert.Error(t, r.Check())
+
+	timeout = 600
+	r.Force = &enabled
+	assert.Error(t, r.Check())
+}
+
+func TestStartupPolicyVerify(t *testing.T) {
+	enabled := true
+	url := "https://api.testnet.solana.com"
+
+	s := StartupPolicy{VerifyStartup: &enabled}
+
+	assert.Error(t, s.Check(nil))
+	assert.Error(t, s.Check(&solana.Environment{}))
+
+	env := &solana.Environment{RPCURL: &url}
+	assert.NoError(t, s.Check(env))
+
+	vars := s.VerifyEnv(env, false).Map()
+
+	assert.Equal(t, "true", vars["VERIFY_STARTUP"])
+	assert.Equal(t, "false", vars["VERIFY_VOTING"])
+	assert.Equal(t, "150", vars["MAX_SLOT_DISTANCE"])
+	assert.Equal(t, "600", vars["VERIFY_TIMEOUT"])
+	assert.Equal(t, url, vars["REFERENCE_RPC_URL"])
+
+	assert.Empty(t, (&StartupPolicy{}).VerifyEnv(env, true).Map())
+
+	s.VerifyVoting = &enabled
+	assert.Equal(t, "true", s.VerifyEnv(env, false).Map()["VERIFY_VOTING"])
+}
@@ -0,0 +1,94 @@
+package agave
+
+import (
+	_ "embed"
+	"fmt"
+
+	"dario.cat/mergo"
+	"github.com/BurntSushi/toml"
+	"github.com/pulumi/pulumi-go-provider/infer"
+)
+
+//go:embed defaults/profiles.toml
+var defaultProfilesToml []byte
+
+// Profile is the role a validator plays.  It supplies default flags,
+// which the validator's own Flags are merged over.
+type Profile string
+
+const (
+	ProfileVoting     Profile = "voting"
+	ProfileRPC        Profile = "rpc"
+	ProfileArchiveRPC Profile = "archive-rpc"
+	ProfileBootstrap  Profile = "bootstrap"
+)
+
+func (Profile) Values() []infer.EnumValue[Profile] {
+	return []infer.EnumValue[Profile]{
+		{
+			Name:        string(ProfileVoting),
+			Value:       ProfileVoting,
+			Description: "A voting validator",
+		},
+		{
+			Name:        string(ProfileRPC),
+			Value:       ProfileRPC,
+			Description: "A non-voting RPC node",
+		},
+		{
+			Name:        string(ProfileArchiveRPC),
+			Value:       ProfileArchiveRPC,
+			Description: "A non-voting RPC node keeping the full ledger",
+		},
+		{
+			Name:        string(ProfileBootstrap),
+			Value:       ProfileBootstrap,
+			Description: "The bootstrap validator of a new cluster",
+		},
+	}
+}
+
+func (p Profile) Check() error {
+	switch p {
+	case ProfileVoting, ProfileRPC, ProfileArchiveRPC, ProfileBootstrap:
+	default:
+		return fmt.Errorf("unknown validator profile '%s'", p)
+	}
+
+	return nil
+}
+
+// NewDefaultFlags returns the default flags for profile.
+func NewDefaultFlags(profile Profile) (*Flags, error) {
+	if err := profile.Check(); err != nil {
+		return nil, err
+	}
+
+	var defaults map[Profile]Flags
+
+	if err := toml.Unmarshal(defaultProfilesToml, &defaults); err != nil {
+		return nil, err
+	}
+
+	f := defaults[profile]
+
+	return &f, nil
+}
+
+// ResolveFlags returns profile's default flags with f merged over
+// them.  Flags set in f, including pointers to false or zero, win over
+// the defaults.  Flags that aren't pointers only win when they aren't
+// zero, so profiles only default those whose zero value means unset.
+func (f Flags) ResolveFlags(profile Profile) (*Flags, error) {
+	res, err := NewDefaultFlags(profile)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := mergo.Merge(res, f, mergo.WithOverride, mergo.WithoutDereference); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
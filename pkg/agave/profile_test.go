@@ -0,0 +1,66 @@
+package agave
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultFlags(t *testing.T) {
+	s, err := LoadFlagSchema()
+	require.NoError(t, err)
+
+	for _, v := range Profile("").Values() {
+		f, err := NewDefaultFlags(v.Value)
+		require.NoError(t, err, v.Name)
+
+		_, err = s.Validate(VariantAgave, nil, f.Args())
+		assert.NoError(t, err, v.Name)
+	}
+
+	f, err := NewDefaultFlags(ProfileRPC)
+	require.NoError(t, err)
+
+	require.NotNil(t, f.NoVoting)
+	assert.True(t, *f.NoVoting)
+	assert.Equal(t, []string{"program-id", "spl-token-owner", "spl-token-mint"}, *f.AccountIndex)
+
+	_, err = NewDefaultFlags("validator")
+	assert.Error(t, err)
+}
+
+func TestResolveFlags(t *testing.T) {
+	voting := false
+	index := []string{"program-id"}
+
+	user := Flags{
+		NoVoting:     &voting,
+		AccountIndex: &index,
+		RpcPort:      8899,
+	}
+
+	f, err := user.ResolveFlags(ProfileRPC)
+	require.NoError(t, err)
+
+	assert.False(t, *f.NoVoting)
+	assert.Equal(t, index, *f.AccountIndex)
+	assert.Equal(t, 8899, f.RpcPort)
+	assert.True(t, *f.FullRpcAPI)
+	assert.Equal(t, "skip_any_corrupted_record", f.WalRecoveryMode)
+}
+
+func TestResolveFlagsOverrideFalse(t *testing.T) {
+	wait := false
+
+	user := Flags{NoWaitForVoteToStartLeader: &wait}
+
+	f, err := Flags{}.ResolveFlags(ProfileBootstrap)
+	require.NoError(t, err)
+	assert.True(t, *f.NoWaitForVoteToStartLeader)
+
+	f, err = user.ResolveFlags(ProfileBootstrap)
+	require.NoError(t, err)
+	assert.False(t, *f.NoWaitForVoteToStartLeader)
+	assert.NotContains(t, f.Args(), "--no-wait-for-vote-to-start-leader")
+}
@@ -0,0 +1,171 @@
+package agave
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+
+	"github.com/abklabs/svmkit/pkg/runner"
+)
+
+var (
+	fullSnapshotRegexp        = regexp.MustCompile(`^snapshot-[0-9]+-[1-9A-HJ-NP-Za-km-z]+\.tar(\.(zst|bz2|gz|lz4))?$`)
+	incrementalSnapshotRegexp = regexp.MustCompile(`^incremental-snapshot-[0-9]+-[0-9]+-[1-9A-HJ-NP-Za-km-z]+\.tar(\.(zst|bz2|gz|lz4))?$`)
+	sha256Regexp              = regexp.MustCompile(`^[0-9a-f]{64}$`)
+)
+
+// SnapshotFile is a snapshot archive, either a local file sent to the
+// host with the rest of the install, or a URL the host downloads it
+// from.  URLs need a SHA256 to check the download against.
+type SnapshotFile struct {
+	Path   *string `pulumi:"path,optional"`
+	URL    *string `pulumi:"url,optional"`
+	SHA256 *string `pulumi:"sha256,optional"`
+}
+
+func (s *SnapshotFile) check(what string, nameRegexp *regexp.Regexp) error {
+	if (s.Path == nil) == (s.URL == nil) {
+		return fmt.Errorf("the %s snapshot needs exactly one of a path or a URL", what)
+	}
+
+	if s.SHA256 != nil && !sha256Regexp.MatchString(*s.SHA256) {
+		return fmt.Errorf("invalid %s snapshot sha256 '%s'", what, *s.SHA256)
+	}
+
+	if p := s.Path; p != nil {
+		if !nameRegexp.MatchString(filepath.Base(*p)) {
+			return fmt.Errorf("'%s' isn't named like a %s snapshot", *p, what)
+		}
+	}
+
+	if u := s.URL; u != nil {
+		parsed, err := url.ParseRequestURI(*u)
+
+		if err != nil {
+			return fmt.Errorf("invalid %s snapshot URL '%s': %w", what, *u, err)
+		}
+
+		if parsed.Scheme != "http" && parsed.Scheme != "https" {
+			return fmt.Errorf("%s snapshot URL '%s' must be http or https", what, *u)
+		}
+
+		if s.SHA256 == nil {
+			return fmt.Errorf("the %s snapshot URL needs a sha256", what)
+		}
+	}
+
+	return nil
+}
+
+func (s *SnapshotFile) env(b *runner.EnvBuilder, prefix string) {
+	if p := s.Path; p != nil {
+		b.Set(prefix+"_FILE", s.payloadPath())
+	}
+
+	b.SetP(prefix+"_URL", s.URL)
+	b.SetP(prefix+"_SHA256", s.SHA256)
+}
+
+func (s *SnapshotFile) payloadPath() string {
+	return path.Join("snapshots", filepath.Base(*s.Path))
+}
+
+// SnapshotSource seeds the ledger with snapshots before the validator
+// first starts, rather than leaving it to fetch one from the known
+// validators.  Snapshots come either from Full and Incremental, or
+// from the latest snapshots served over RPC by RPCHost, another
+// validator in the fleet.  Snapshots from RPCHost have no checksum;
+// their names must match the slots the host advertises, and agave
+// checks them against the hash in the name when it loads them.  The
+// install fails if no full snapshot can be seeded, but goes on
+// without an incremental one.  A ledger that already has a full
+// snapshot is left alone.
+type SnapshotSource struct {
+	Full        *SnapshotFile `pulumi:"full,optional"`
+	Incremental *SnapshotFile `pulumi:"incremental,optional"`
+	// RPCHost is the "host:port" of the validator's RPC.
+	RPCHost *string `pulumi:"rpcHost,optional"`
+}
+
+func (s *SnapshotSource) Check() error {
+	if h := s.RPCHost; h != nil {
+		if s.Full != nil || s.Incremental != nil {
+			return fmt.Errorf("snapshots come from either an RPC host or files, not both")
+		}
+
+		if _, _, err := net.SplitHostPort(*h); err != nil {
+			return fmt.Errorf("invalid snapshot RPC host '%s': %w", *h, err)
+		}
+
+		return nil
+	}
+
+	if s.Full == nil {
+		return fmt.Errorf("a snapshot source needs a full snapshot or an RPC host")
+	}
+
+	if err := s.Full.check("full", fullSnapshotRegexp); err != nil {
+		return err
+	}
+
+	if i := s.Incremental; i != nil {
+		if err := i.check("incremental", incrementalSnapshotRegexp); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Env sets where the snapshots come from, and the directories the
+// validator looks for them in.
+func (s *SnapshotSource) Env(flags Flags, paths Paths) *runner.EnvBuilder {
+	b := runner.NewEnvBuilder()
+
+	fullDir := paths.Ledger
+	if p := flags.FullSnapshotArchivePath; p != nil {
+		fullDir = *p
+	}
+
+	incrementalDir := fullDir
+	if p := flags.IncrementalSnapshotArchivePath; p != nil {
+		incrementalDir = *p
+	}
+
+	b.Set("SNAPSHOT_FULL_DIR", fullDir)
+	b.Set("SNAPSHOT_INCREMENTAL_DIR", incrementalDir)
+	b.SetP("SNAPSHOT_RPC_HOST", s.RPCHost)
+
+	if f := s.Full; f != nil {
+		f.env(b, "SNAPSHOT_FULL")
+	}
+
+	if i := s.Incremental; i != nil {
+		i.env(b, "SNAPSHOT_INCREMENTAL")
+	}
+
+	return b
+}
+
+// AddToPayload adds the local snapshot files.
+func (s *SnapshotSource) AddToPayload(p *runner.Payload) error {
+	for _, f := range []*SnapshotFile{s.Full, s.Incremental} {
+		if f == nil || f.Path == nil {
+			continue
+		}
+
+		r, err := os.Open(*f.Path)
+
+		if err != nil {
+			return err
+		}
+
+		p.AddReader(f.payloadPath(), r)
+	}
+
+	return nil
+}
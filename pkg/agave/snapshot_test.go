@@ -0,0 +1,59 @@
+package agave
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/abklabs/svmkit/pkg/runner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotSourceCheck(t *testing.T) {
+	sha := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	fullURL := "https://snapshots.example.com/snapshot-100-3ZVJrMbDqpf6yj3hPiaWHSYm5D4t1R6sdN2bybdDHpG2.tar.zst"
+	badURL := "ftp://snapshots.example.com/snapshot.tar.zst"
+	badPath := "/tmp/latest.tar.zst"
+	host := "10.0.0.5:8899"
+
+	for _, s := range []SnapshotSource{
+		{Full: &SnapshotFile{URL: &fullURL, SHA256: &sha}},
+		{RPCHost: &host},
+	} {
+		assert.NoError(t, s.Check())
+	}
+
+	for _, s := range []SnapshotSource{
+		{},
+		{Full: &SnapshotFile{}},
+		{Full: &SnapshotFile{URL: &fullURL}},
+		{Full: &SnapshotFile{URL: &badURL, SHA256: &sha}},
+		{Full: &SnapshotFile{Path: &badPath}},
+		{Full: &SnapshotFile{URL: &fullURL, SHA256: &sha}, RPCHost: &host},
+	} {
+		assert.Error(t, s.Check())
+	}
+}
+
+func TestSnapshotSourcePayload(t *testing.T) {
+	dir := t.TempDir()
+	full := filepath.Join(dir, "snapshot-100-3ZVJrMbDqpf6yj3hPiaWHSYm5D4t1R6sdN2bybdDHpG2.tar.zst")
+	require.NoError(t, os.WriteFile(full, []byte("snapshot"), 0644))
+
+	archive := "/srv/snapshots"
+
+	s := SnapshotSource{Full: &SnapshotFile{Path: &full}}
+	require.NoError(t, s.Check())
+
+	env := s.Env(Flags{FullSnapshotArchivePath: &archive}, NewPaths(nil)).Map()
+
+	assert.Equal(t, "snapshots/"+filepath.Base(full), env["SNAPSHOT_FULL_FILE"])
+	assert.Equal(t, archive, env["SNAPSHOT_FULL_DIR"])
+	assert.Equal(t, archive, env["SNAPSHOT_INCREMENTAL_DIR"])
+
+	p := &runner.Payload{}
+	require.NoError(t, s.AddToPayload(p))
+	require.Len(t, p.Files, 1)
+	assert.Equal(t, "snapshots/"+filepath.Base(full), p.Files[0].Path)
+}
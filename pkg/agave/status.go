@@ -0,0 +1,41 @@
+package agave
+
+import (
+	"github.com/abklabs/svmkit/pkg/runner"
+)
+
+// StartupStatusName is the name the startup verification reports its
+// StartupStatus under.
+const StartupStatusName = "agave-startup"
+
+// StartupStatus is what the startup verification last found.  Slots
+// are nil where an RPC endpoint couldn't be queried.
+type StartupStatus struct {
+	LocalSlot     *int64 `json:"localSlot"`
+	ReferenceSlot *int64 `json:"referenceSlot"`
+	SlotDistance  *int64 `json:"slotDistance"`
+	CaughtUp      bool   `json:"caughtUp"`
+	Gossiping     bool   `json:"gossiping"`
+	// Voting is nil for a validator started without voting.
+	Voting   *bool  `json:"voting"`
+	LastVote *int64 `json:"lastVote"`
+}
+
+func (s *StartupStatus) Healthy() bool {
+	return s.CaughtUp && s.Gossiping && (s.Voting == nil || *s.Voting)
+}
+
+// ParseStartupStatus returns the startup status a command, such as
+// InstallCommand or UpdateCommand, reported in its result, or nil if
+// there was none.
+func ParseStartupStatus(result *runner.Result) (*StartupStatus, error) {
+	var s StartupStatus
+
+	ok, err := result.Status(StartupStatusName, &s)
+
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	return &s, nil
+}
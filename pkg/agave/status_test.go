@@ -0,0 +1,37 @@
+package agave
+
+import (
+	"testing"
+
+	"github.com/abklabs/svmkit/pkg/runner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStartupStatus(t *testing.T) {
+	result := &runner.Result{Lines: []string{
+		`svmkit::status agave-startup {"localSlot":null,"referenceSlot":1000,"slotDistance":null,"caughtUp":false,"gossiping":true,"voting":false,"lastVote":null}`,
+		`svmkit::status agave-startup {"localSlot":990,"referenceSlot":1000,"slotDistance":10,"caughtUp":true,"gossiping":true,"voting":true,"lastVote":998}`,
+	}}
+
+	s, err := ParseStartupStatus(result)
+	require.NoError(t, err)
+	require.NotNil(t, s)
+
+	assert.True(t, s.Healthy())
+	assert.Equal(t, int64(10), *s.SlotDistance)
+	assert.Equal(t, int64(998), *s.LastVote)
+
+	s, err = ParseStartupStatus(&runner.Result{Lines: result.Lines[:1]})
+	require.NoError(t, err)
+	assert.False(t, s.Healthy())
+	assert.Nil(t, s.LocalSlot)
+
+	s, err = ParseStartupStatus(&runner.Result{})
+	require.NoError(t, err)
+	assert.Nil(t, s)
+
+	s, err = ParseStartupStatus(nil)
+	require.NoError(t, err)
+	assert.Nil(t, s)
+}
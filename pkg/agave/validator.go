@@ -4,24 +4,19 @@ import (
 	_ "embed"
 	"fmt"
 	"net"
+	"path"
+	"strconv"
 	"strings"
 
 	"github.com/abklabs/svmkit/pkg/agave/geyser"
 	"github.com/abklabs/svmkit/pkg/deletion"
+	"github.com/abklabs/svmkit/pkg/logging"
+	"github.com/abklabs/svmkit/pkg/machine/user"
 	"github.com/abklabs/svmkit/pkg/runner"
-	"github.com/abklabs/svmkit/pkg/runner/deb"
 	"github.com/abklabs/svmkit/pkg/solana"
 	"github.com/abklabs/svmkit/pkg/validator"
 )
 
-const (
-	accountsPath = "/home/sol/accounts"
-	ledgerPath   = "/home/sol/ledger"
-
-	identityKeyPairPath    = "/home/sol/validator-keypair.json"
-	voteAccountKeyPairPath = "/home/sol/vote-account-keypair.json"
-)
-
 type KeyPairs struct {
 	Identity    string `pulumi:"identity" provider:"secret"`
 	VoteAccount string `pulumi:"voteAccount" provider:"secret"`
@@ -70,9 +65,20 @@ type InstallCommand struct {
 	Agave
 
 	packageInfo *PackageInfo
+	warnings    []string
+}
+
+// Warnings returns what Check found worth telling the user about,
+// such as deprecated flags, that doesn't stop the install.
+func (cmd *InstallCommand) Warnings() []string {
+	return cmd.warnings
 }
 
 func (cmd *InstallCommand) Check() error {
+	if err := cmd.ServiceUser.Check(); err != nil {
+		return err
+	}
+
 	if m := cmd.Metrics; m != nil {
 		if err := m.Check(); err != nil {
 			return fmt.Errorf("warning: invalid metrics URL: %v", err)
@@ -86,8 +92,22 @@ func (cmd *InstallCommand) Check() error {
 
 	}
 
+	if err := geyser.CheckPlugins(cmd.GeyserPlugins); err != nil {
+		return fmt.Errorf("warning: invalid geyser plugin config: %v", err)
+	}
+
 	cmd.SetConfigDefaults()
 
+	if p := cmd.Profile; p != nil {
+		flags, err := cmd.Flags.ResolveFlags(*p)
+
+		if err != nil {
+			return err
+		}
+
+		cmd.Flags = *flags
+	}
+
 	packageInfo, err := GeneratePackageInfo(cmd.GetVariant(), cmd.Version)
 
 	if err != nil {
@@ -96,11 +116,8 @@ func (cmd *InstallCommand) Check() error {
 
 	cmd.Variant = &packageInfo.Variant
 
-	if g := cmd.GeyserPlugin; g != nil {
-		if g.YellowstoneGRPC != nil {
-			yellowstoneVersion := g.YellowstoneGRPC.Version
-			packageInfo.PackageGroup.Add(deb.Package{Name: "svmkit-yellowstone_grpc", Version: &yellowstoneVersion})
-		}
+	for _, c := range cmd.geyserPluginConfigs(cmd.Paths()) {
+		packageInfo.PackageGroup.Add(c.plugin.Packages()...)
 	}
 
 	if err := cmd.UpdatePackageGroup(packageInfo.PackageGroup); err != nil {
@@ -109,6 +126,30 @@ func (cmd *InstallCommand) Check() error {
 
 	cmd.packageInfo = packageInfo
 
+	if j := cmd.Jito; j != nil {
+		if packageInfo.Variant != VariantJito {
+			return fmt.Errorf("jito configuration is only supported by the jito variant, not '%s'", packageInfo.Variant)
+		}
+
+		if err := j.Check(); err != nil {
+			return err
+		}
+	}
+
+	schema, err := LoadFlagSchema()
+
+	if err != nil {
+		return err
+	}
+
+	warnings, err := schema.Validate(packageInfo.Variant, cmd.Version, cmd.Args(cmd.Paths()))
+
+	if err != nil {
+		return err
+	}
+
+	cmd.warnings = warnings
+
 	policy := cmd.GetDeletionPolicy()
 	if err := policy.Check(); err != nil {
 		return err
@@ -116,6 +157,28 @@ func (cmd *InstallCommand) Check() error {
 
 	cmd.DeletionPolicy = &policy
 
+	if s := cmd.StartupPolicy; s != nil {
+		if err := s.Check(cmd.Environment); err != nil {
+			return err
+		}
+	}
+
+	if s := cmd.SnapshotSource; s != nil {
+		if err := s.Check(); err != nil {
+			return err
+		}
+	}
+
+	if r := cmd.RestartPolicy; r != nil {
+		if err := r.Check(); err != nil {
+			return err
+		}
+	}
+
+	if err := cmd.Logging.CheckFor(logging.ProfileValidator); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -128,24 +191,15 @@ func (cmd *InstallCommand) Env() *runner.EnvBuilder {
 
 	b := runner.NewEnvBuilder()
 
-	// Add plugin flag that points to config file that get's created by the runner
-	if g := cmd.GeyserPlugin; g != nil {
-		if cmd.Flags.GeyserPluginConfig == nil {
-			pluginConfig := []string{"/home/sol/geyser-config.json"}
-			cmd.Flags.GeyserPluginConfig = &pluginConfig
-		} else {
-			pluginConfig := append(*cmd.Flags.GeyserPluginConfig, "/home/sol/geyser-config.json")
-			cmd.Flags.GeyserPluginConfig = &pluginConfig
-		}
-	}
+	paths := cmd.Paths()
 
 	b.SetMap(map[string]string{
-		"VALIDATOR_FLAGS": strings.Join(cmd.Flags.Args(), " "),
+		"VALIDATOR_FLAGS": strings.Join(cmd.Args(paths), " "),
 		"VALIDATOR_ENV":   validatorEnv.String(),
 	})
 
 	{
-		s := identityKeyPairPath
+		s := paths.IdentityKeyPair
 		conf := solana.CLIConfig{
 			KeyPair: &s,
 		}
@@ -166,6 +220,7 @@ func (cmd *InstallCommand) Env() *runner.EnvBuilder {
 	b.Set("VALIDATOR_PACKAGE", cmd.packageInfo.Variant.PackageName())
 	b.Set("VALIDATOR_SERVICE", cmd.packageInfo.Variant.ServiceName())
 	b.Merge(cmd.RunnerCommand.Env())
+	b.Merge(cmd.ServiceUser.Env())
 
 	b.Set("RPC_BIND_ADDRESS", cmd.Flags.RpcBindAddress)
 	b.SetInt("RPC_PORT", cmd.Flags.RpcPort)
@@ -174,6 +229,11 @@ func (cmd *InstallCommand) Env() *runner.EnvBuilder {
 		b.SetBoolP("WAIT_FOR_RPC_HEALTH", cmd.StartupPolicy.WaitForRPCHealth)
 	}
 
+	if s := cmd.StartupPolicy; s != nil {
+		b.SetFloat64P("CLOCK_MAX_OFFSET", s.MaxClockOffset)
+		b.Merge(s.VerifyEnv(cmd.Environment, cmd.Flags.NoVoting == nil || !*cmd.Flags.NoVoting))
+	}
+
 	if i := cmd.Info; i != nil {
 		b.Set("VALIDATOR_INFO_NAME", i.Name)
 		b.SetP("VALIDATOR_INFO_WEBSITE", i.Website)
@@ -185,20 +245,46 @@ func (cmd *InstallCommand) Env() *runner.EnvBuilder {
 		b.SetArray("VALIDATOR_EXIT_FLAGS", s.Flags().Args())
 	}
 
-	b.Set("LEDGER_PATH", ledgerPath)
+	if r := cmd.RestartPolicy; r != nil {
+		b.Merge(r.Env())
+	}
 
-	if g := cmd.GeyserPlugin; g != nil {
-		if g.YellowstoneGRPC != nil {
-			b.SetBool("YELLOWSTONE_GRPC", true)
+	b.Set("LEDGER_PATH", paths.Ledger)
+
+	if s := cmd.SnapshotSource; s != nil {
+		b.Merge(s.Env(cmd.Flags, paths))
+	}
 
-			if g.YellowstoneGRPC.Config != nil {
-				address := g.YellowstoneGRPC.Config.Grpc.Address
-				_, port, err := net.SplitHostPort(address)
-				if err == nil {
+	{
+		var files, dests, checks []string
+
+		var tlsFiles, tlsPaths, tlsSelfSigned []string
+
+		for _, c := range cmd.geyserPluginConfigs(paths) {
+			files = append(files, c.payloadPath)
+			dests = append(dests, c.path)
+			checks = append(checks, strconv.FormatBool(c.plugin.ConfigCheck()))
+
+			if t := c.plugin.TLS(); t != nil && t.Provisioned() {
+				tlsFiles = append(tlsFiles, geyserTLSPath(c.payloadPath, ""))
+				tlsPaths = append(tlsPaths, geyserTLSPath(c.path, ""))
+				tlsSelfSigned = append(tlsSelfSigned, strconv.FormatBool(t.IsSelfSigned()))
+			}
+
+			if y := c.plugin.YellowstoneGRPC; y != nil && y.Config != nil {
+				if _, port, err := net.SplitHostPort(y.Config.Grpc.Address); err == nil {
 					b.Set("YELLOWSTONE_GRPC_PORT", port)
 				}
 			}
 		}
+
+		b.Set("GEYSER_PLUGIN_DIR", paths.GeyserPlugins)
+		b.SetArray("GEYSER_CONFIG_FILES", files)
+		b.SetArray("GEYSER_CONFIG_PATHS", dests)
+		b.SetArray("GEYSER_CONFIG_CHECK", checks)
+		b.SetArray("GEYSER_TLS_FILES", tlsFiles)
+		b.SetArray("GEYSER_TLS_PATHS", tlsPaths)
+		b.SetArray("GEYSER_TLS_SELF_SIGNED", tlsSelfSigned)
 	}
 
 	cmd.DeletionPolicy.Create(&cmd.Agave, b)
@@ -213,6 +299,12 @@ func (cmd *InstallCommand) AddToPayload(p *runner.Payload) error {
 		return err
 	}
 
+	return cmd.addValidatorFiles(p)
+}
+
+// addValidatorFiles adds everything but the steps script, which is
+// shared between installing and updating the validator.
+func (cmd *InstallCommand) addValidatorFiles(p *runner.Payload) error {
 	if err := p.AddTemplate("check-validator", checkValidatorScriptTmpl, cmd); err != nil {
 		return err
 	}
@@ -224,21 +316,69 @@ func (cmd *InstallCommand) AddToPayload(p *runner.Payload) error {
 	p.AddString("validator-keypair.json", cmd.KeyPairs.Identity)
 	p.AddString("vote-account-keypair.json", cmd.KeyPairs.VoteAccount)
 
-	if plugin := cmd.GeyserPlugin; plugin != nil {
-		confString, err := plugin.ToConfigString()
+	for _, c := range cmd.geyserPluginConfigs(cmd.Paths()) {
+		confString, err := c.config()
 		if err != nil {
 			return err
 		}
-		p.AddString("geyser-config.json", confString)
+		p.AddString(c.payloadPath, confString)
+
+		if t := c.plugin.TLS(); t != nil && t.Cert != nil {
+			p.AddString(geyserTLSPath(c.payloadPath, ".crt"), *t.Cert)
+			p.AddString(geyserTLSPath(c.payloadPath, ".key"), *t.Key)
+		}
+	}
+
+	lib, err := assets.Open(assetsValidatorLib)
+
+	if err != nil {
+		return err
+	}
+
+	p.AddReader("validator-lib.sh", lib)
+
+	if s := cmd.SnapshotSource; s != nil {
+		if err := s.AddToPayload(p); err != nil {
+			return err
+		}
 	}
 
 	if err := deletion.AddToPayload(p); err != nil {
 		return err
 	}
 
+	logConfig, err := cmd.Logging.Resolve(logging.ProfileValidator)
+
+	if err != nil {
+		return err
+	}
+
+	if err := logConfig.AddToPayload(p, cmd.packageInfo.Variant.ServiceName(), cmd.LogFiles()...); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// UpdateCommand updates an installed validator in place.  Rather than
+// running the whole install, it only replaces what differs from the
+// host, and restarts the validator once if any of it did.
+type UpdateCommand struct {
+	InstallCommand
+}
+
+func (cmd *UpdateCommand) AddToPayload(p *runner.Payload) error {
+	updateScript, err := assets.Open(assetsUpdateScript)
+
+	if err != nil {
+		return err
+	}
+
+	p.AddReader(runner.ScriptNameSteps, updateScript)
+
+	return cmd.addValidatorFiles(p)
+}
+
 type UninstallCommand struct {
 	Agave
 
@@ -260,6 +400,10 @@ func (u *UninstallCommand) AddToPayload(p *runner.Payload) error {
 		return err
 	}
 
+	if err := logging.AddToPayload(p); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -304,6 +448,7 @@ func (u *UninstallCommand) Env() *runner.EnvBuilder {
 
 	b.Set("VALIDATOR_SERVICE", u.packageInfo.Variant.ServiceName())
 	b.Merge(u.RunnerCommand.Env())
+	b.Merge(u.ServiceUser.Env())
 
 	u.DeletionPolicy.Delete(&u.Agave, b)
 
@@ -317,14 +462,21 @@ type Agave struct {
 	Version        *string               `pulumi:"version,optional"`
 	Variant        *Variant              `pulumi:"variant,optional"`
 	KeyPairs       KeyPairs              `pulumi:"keyPairs"`
+	Profile        *Profile              `pulumi:"profile,optional"`
 	Flags          Flags                 `pulumi:"flags"`
 	Metrics        *Metrics              `pulumi:"metrics,optional"`
 	Info           *solana.ValidatorInfo `pulumi:"info,optional"`
 	TimeoutConfig  *TimeoutConfig        `pulumi:"timeoutConfig,optional"`
 	StartupPolicy  *StartupPolicy        `pulumi:"startupPolicy,optional"`
 	ShutdownPolicy *ShutdownPolicy       `pulumi:"shutdownPolicy,optional"`
+	RestartPolicy  *RestartPolicy        `pulumi:"restartPolicy,optional"`
 	GeyserPlugin   *geyser.GeyserPlugin  `pulumi:"geyserPlugin,optional"`
+	GeyserPlugins  []geyser.GeyserPlugin `pulumi:"geyserPlugins,optional"`
 	DeletionPolicy *deletion.Policy      `pulumi:"deletionPolicy,optional"`
+	ServiceUser    *user.ServiceUser     `pulumi:"serviceUser,optional"`
+	Logging        *logging.Config       `pulumi:"logging,optional"`
+	Jito           *Jito                 `pulumi:"jito,optional"`
+	SnapshotSource *SnapshotSource       `pulumi:"snapshotSource,optional"`
 }
 
 func (agave *Agave) Install() runner.Command {
@@ -333,6 +485,14 @@ func (agave *Agave) Install() runner.Command {
 	}
 }
 
+func (agave *Agave) Update() runner.Command {
+	return &UpdateCommand{
+		InstallCommand: InstallCommand{
+			Agave: *agave,
+		},
+	}
+}
+
 func (agave *Agave) GetVariant() Variant {
 	if agave.Variant == nil {
 		return VariantAgave
@@ -361,6 +521,82 @@ func (agave *Agave) Uninstall() runner.Command {
 	}
 }
 
+func (agave *Agave) Paths() Paths {
+	return NewPaths(agave.ServiceUser)
+}
+
+// Args returns the validator's flags, including those of its variant's
+// configuration.
+func (agave *Agave) Args(p Paths) []string {
+	args := agave.Flags.ArgsWithPaths(p)
+
+	if j := agave.Jito; j != nil {
+		args = append(args, j.Flags().Args()...)
+	}
+
+	for _, c := range agave.geyserPluginConfigs(p) {
+		args = append(args, "--geyser-plugin-config", c.path)
+	}
+
+	return args
+}
+
+// geyserPluginConfig is one of the validator's geyser plugins, with
+// the name of its config in the payload and its path on the host.
+type geyserPluginConfig struct {
+	plugin      *geyser.GeyserPlugin
+	payloadPath string
+	path        string
+}
+
+// geyserPluginConfigs returns GeyserPlugin, whose config keeps the
+// path it has always had, followed by each of GeyserPlugins.
+func (agave *Agave) geyserPluginConfigs(p Paths) []geyserPluginConfig {
+	var configs []geyserPluginConfig
+
+	if g := agave.GeyserPlugin; g != nil {
+		configs = append(configs, geyserPluginConfig{g, "geyser-config.json", p.GeyserConfig})
+	}
+
+	for i := range agave.GeyserPlugins {
+		g := &agave.GeyserPlugins[i]
+		name := g.GetName() + ".json"
+
+		configs = append(configs, geyserPluginConfig{g, path.Join("geyser", name), path.Join(p.GeyserPlugins, name)})
+	}
+
+	return configs
+}
+
+// geyserTLSPath returns where a plugin's TLS certificate or key, as given
+// by ext, goes: next to its config, both in the payload and the host.
+func geyserTLSPath(config, ext string) string {
+	return strings.TrimSuffix(config, ".json") + ext
+}
+
+// config returns the plugin's config, pointing at its TLS certificate
+// and key if SVMKit installs them.
+func (c geyserPluginConfig) config() (string, error) {
+	plugin := c.plugin
+
+	if t := plugin.TLS(); t != nil && t.Provisioned() {
+		plugin = plugin.WithTLSPaths(geyserTLSPath(c.path, ".crt"), geyserTLSPath(c.path, ".key"))
+	}
+
+	return plugin.ToConfigString()
+}
+
+// LogFiles returns the log files the validator is configured to write.
+func (agave *Agave) LogFiles() []string {
+	if l := agave.Flags.Log; l != nil && *l != "-" {
+		return []string{*l}
+	}
+
+	return nil
+}
+
 func (agave *Agave) ManagedFiles() []string {
-	return []string{accountsPath, ledgerPath}
+	paths := agave.Paths()
+
+	return []string{paths.Accounts, paths.Ledger}
 }
@@ -1,9 +1,20 @@
 package agave
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
 	"testing"
+	"time"
 
+	"github.com/abklabs/svmkit/pkg/agave/geyser"
+	"github.com/abklabs/svmkit/pkg/machine/user"
+	"github.com/abklabs/svmkit/pkg/runner"
+	"github.com/abklabs/svmkit/pkg/runner/runnertest"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestValidatorEnv(t *testing.T) {
@@ -207,7 +218,7 @@ func TestValidatorFlags(t *testing.T) {
 		NoIncrementalSnapshots:              &noIncrementalSnapshots,
 		NoSnapshotFetch:                     &noSnapshotFetch,
 		NoVoting:                            &noVoting,
-		NoWaitForVoteToStartLeader:          noWaitForVoteToStartLeader,
+		NoWaitForVoteToStartLeader:          &noWaitForVoteToStartLeader,
 		OnlyKnownRPC:                        &onlyKnownRPC,
 		PrivateRPC:                          &privateRPC,
 		PublicRpcAddress:                    &publicRpcAddress,
@@ -408,3 +419,145 @@ func TestValidatorFlags(t *testing.T) {
 
 	assert.Equal(t, expectedArgs, actualArgs)
 }
+
+func TestValidatorPaths(t *testing.T) {
+	name := "validator"
+	home := "/srv/validator"
+
+	a := Agave{ServiceUser: &user.ServiceUser{Name: &name, Home: &home}}
+
+	assert.Equal(t, []string{"/srv/validator/accounts", "/srv/validator/ledger"}, a.ManagedFiles())
+
+	args := a.Flags.ArgsWithPaths(a.Paths())
+
+	assert.Equal(t, []string{
+		"--identity", "/srv/validator/validator-keypair.json",
+		"--vote-account", "/srv/validator/vote-account-keypair.json",
+		"--accounts", "/srv/validator/accounts",
+		"--ledger", "/srv/validator/ledger",
+	}, args[:8])
+}
+
+func TestUpdatePayload(t *testing.T) {
+	a := Agave{KeyPairs: KeyPairs{Identity: "[1]", VoteAccount: "[2]"}}
+
+	cmd := a.Update()
+	require.NoError(t, cmd.Check())
+
+	files := runnertest.PayloadFiles(t, cmd)
+
+	for _, name := range []string{"validator-lib.sh", "check-validator", "validator-keypair.json", "vote-account-keypair.json"} {
+		assert.Contains(t, files, name)
+	}
+
+	update, err := assets.ReadFile(assetsUpdateScript)
+	require.NoError(t, err)
+
+	assert.Equal(t, string(update), files[runner.ScriptNameSteps])
+}
+
+func TestGeyserPlugins(t *testing.T) {
+	legacy := `{"libpath":"/usr/lib/libsolana_accountsdb_plugin_postgres.so"}`
+	kafkaName := "kafka"
+	kafka := `{"libpath":"/usr/lib/libsolana_accountsdb_plugin_kafka.so"}`
+	yellowstone := `{"grpc":{"address":"0.0.0.0:10000"}}`
+
+	a := Agave{
+		KeyPairs:     KeyPairs{Identity: "[1]", VoteAccount: "[2]"},
+		GeyserPlugin: &geyser.GeyserPlugin{GenericPluginConfig: &legacy},
+		GeyserPlugins: []geyser.GeyserPlugin{
+			{YellowstoneGRPC: &geyser.YellowstoneGRPC{JSON: &yellowstone, Version: "1.2.3"}},
+			{Name: &kafkaName, GenericPluginConfig: &kafka},
+		},
+	}
+
+	cmd := a.Install()
+	require.NoError(t, cmd.Check())
+
+	args := a.Args(a.Paths())
+	assert.Equal(t, []string{
+		"--geyser-plugin-config", "/home/sol/geyser-config.json",
+		"--geyser-plugin-config", "/home/sol/geyser/yellowstone-grpc.json",
+		"--geyser-plugin-config", "/home/sol/geyser/kafka.json",
+	}, args[len(args)-6:])
+
+	env := cmd.Env().Map()
+	assert.Equal(t, "(geyser-config.json geyser/yellowstone-grpc.json geyser/kafka.json)", env["GEYSER_CONFIG_FILES"])
+	assert.Equal(t, "(false true false)", env["GEYSER_CONFIG_CHECK"])
+	assert.Equal(t, "/home/sol/geyser", env["GEYSER_PLUGIN_DIR"])
+	assert.Contains(t, env["PACKAGE_LIST"], "svmkit-yellowstone_grpc=1.2.3")
+
+	// Env must not add the plugins to the flags a second time.
+	assert.Equal(t, env["VALIDATOR_FLAGS"], cmd.Env().Map()["VALIDATOR_FLAGS"])
+
+	files := runnertest.PayloadFiles(t, cmd)
+
+	for _, name := range []string{"geyser-config.json", "geyser/yellowstone-grpc.json", "geyser/kafka.json"} {
+		assert.Contains(t, files, name)
+	}
+
+	a.GeyserPlugins = append(a.GeyserPlugins, geyser.GeyserPlugin{Name: &kafkaName, GenericPluginConfig: &kafka})
+	assert.Error(t, a.Install().Check())
+}
+
+func testTLSKeyPair(t *testing.T) (string, string) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := x509.Certificate{SerialNumber: big.NewInt(1), NotAfter: time.Now().Add(time.Hour)}
+
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, pub, priv)
+	require.NoError(t, err)
+
+	keyDer, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}))
+}
+
+func TestGeyserPluginTLS(t *testing.T) {
+	selfSigned := true
+	cert, key := testTLSKeyPair(t)
+
+	plugin := func(tls geyser.GrpcConfigGrpcServerTLS) geyser.GeyserPlugin {
+		return geyser.GeyserPlugin{
+			YellowstoneGRPC: &geyser.YellowstoneGRPC{
+				Version: "1.2.3",
+				Config: &geyser.Config{Grpc: geyser.GrpcConfigGrpc{
+					Address:   "0.0.0.0:10000",
+					TLSConfig: &tls,
+				}},
+			},
+		}
+	}
+
+	a := Agave{
+		KeyPairs:      KeyPairs{Identity: "[1]", VoteAccount: "[2]"},
+		GeyserPlugins: []geyser.GeyserPlugin{plugin(geyser.GrpcConfigGrpcServerTLS{SelfSigned: &selfSigned})},
+	}
+
+	cmd := a.Install()
+	require.NoError(t, cmd.Check())
+
+	env := cmd.Env().Map()
+	assert.Equal(t, "(/home/sol/geyser/yellowstone-grpc)", env["GEYSER_TLS_PATHS"])
+	assert.Equal(t, "(true)", env["GEYSER_TLS_SELF_SIGNED"])
+	assert.Contains(t, env["PACKAGE_LIST"], "openssl")
+
+	files := runnertest.PayloadFiles(t, cmd)
+	assert.Contains(t, files["geyser/yellowstone-grpc.json"], `"cert_path": "/home/sol/geyser/yellowstone-grpc.crt"`)
+	assert.NotContains(t, files, "geyser/yellowstone-grpc.crt")
+
+	a.GeyserPlugins = []geyser.GeyserPlugin{plugin(geyser.GrpcConfigGrpcServerTLS{Cert: &cert, Key: &key})}
+
+	cmd = a.Install()
+	require.NoError(t, cmd.Check())
+
+	assert.Equal(t, "(false)", cmd.Env().Map()["GEYSER_TLS_SELF_SIGNED"])
+
+	files = runnertest.PayloadFiles(t, cmd)
+	assert.Equal(t, cert, files["geyser/yellowstone-grpc.crt"])
+	assert.Equal(t, key, files["geyser/yellowstone-grpc.key"])
+	assert.Contains(t, files["geyser/yellowstone-grpc.json"], `"key_path": "/home/sol/geyser/yellowstone-grpc.key"`)
+}
@@ -0,0 +1,15 @@
+package backup
+
+import (
+	"embed"
+)
+
+//go:embed assets
+var assets embed.FS
+
+const (
+	assetsBackupScript    = "assets/svmkit-backup"
+	assetsInstallScript   = "assets/install.sh"
+	assetsUninstallScript = "assets/uninstall.sh"
+	assetsRestoreScript   = "assets/restore.sh"
+)
@@ -0,0 +1,64 @@
+# -*- mode: shell-script -*-
+# shellcheck shell=bash
+
+BACKUP_SERVICE=svmkit-backup.service
+BACKUP_TIMER=svmkit-backup.timer
+
+step::00::wait-for-a-stable-environment() {
+    cloud-init::wait-for-stable-environment
+}
+
+step::10::install-packages() {
+    [[ ${#PACKAGE_LIST[@]} -eq 0 ]] && return 0
+
+    svmkit::apt::get install "${PACKAGE_LIST[@]}"
+}
+
+step::20::create-sol-user() {
+    create-sol-user
+}
+
+step::30::install-backup-script() {
+    local backup_path
+
+    svmkit::sudo install -m 755 -o root -g root svmkit-backup /usr/local/bin/svmkit-backup
+
+    # The configuration may hold S3 credentials.
+    svmkit::sudo install -m 640 -o root -g "$SVMKIT_GROUP" svmkit-backup.conf /etc/svmkit-backup.conf
+
+    # shellcheck disable=SC1091
+    backup_path=$(. ./svmkit-backup.conf && echo "${BACKUP_PATH:-}")
+
+    if [[ -n "$backup_path" ]]; then
+        svmkit::sudo install -d -m 750 -o "$SVMKIT_USER" -g "$SVMKIT_GROUP" "$backup_path"
+    fi
+}
+
+step::40::install-timer() {
+    cat <<EOF | svmkit::sudo tee /etc/systemd/system/"${BACKUP_SERVICE}" >/dev/null
+[Unit]
+Description=SVMkit snapshot and tower backup
+
+[Service]
+Type=oneshot
+User=${SVMKIT_USER}
+Group=${SVMKIT_GROUP}
+ExecStart=/usr/local/bin/svmkit-backup create
+EOF
+
+    cat <<EOF | svmkit::sudo tee /etc/systemd/system/"${BACKUP_TIMER}" >/dev/null
+[Unit]
+Description=SVMkit snapshot and tower backup timer
+
+[Timer]
+OnCalendar=${BACKUP_SCHEDULE}
+Persistent=true
+
+[Install]
+WantedBy=timers.target
+EOF
+
+    svmkit::sudo systemctl daemon-reload
+    svmkit::sudo systemctl enable "${BACKUP_TIMER}"
+    svmkit::sudo systemctl restart "${BACKUP_TIMER}"
+}
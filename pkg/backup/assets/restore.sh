@@ -0,0 +1,38 @@
+# -*- mode: shell-script -*-
+# shellcheck shell=bash
+
+step::00::wait-for-a-stable-environment() {
+    cloud-init::wait-for-stable-environment
+}
+
+step::10::install-packages() {
+    [[ ${#PACKAGE_LIST[@]} -eq 0 ]] && return 0
+
+    svmkit::apt::get install "${PACKAGE_LIST[@]}"
+}
+
+step::20::create-sol-user() {
+    create-sol-user
+}
+
+step::30::check-validator-stopped() {
+    [[ -v VALIDATOR_SERVICE ]] || return 0
+
+    if systemctl is-active --quiet "${VALIDATOR_SERVICE}"; then
+        log::fatal "'${VALIDATOR_SERVICE}' is running; stop it before restoring a backup"
+    fi
+}
+
+step::40::restore-backup() {
+    local full_dir
+
+    # shellcheck disable=SC1091
+    full_dir=$(. ./svmkit-backup.conf && echo "$BACKUP_FULL_SNAPSHOT_DIR")
+
+    if [[ "${RESTORE_FORCE:-false}" != "true" ]] && svmkit::sudo find "$full_dir" -maxdepth 1 -name 'snapshot-*' 2>/dev/null | grep -q .; then
+        log::info "'$full_dir' already has a full snapshot; not restoring"
+        return 0
+    fi
+
+    svmkit::sudo env BACKUP_CONFIG=./svmkit-backup.conf bash ./svmkit-backup restore "${RESTORE_NAME:-}"
+}
@@ -0,0 +1,20 @@
+# -*- mode: shell-script -*-
+# shellcheck shell=bash
+
+BACKUP_SERVICE=svmkit-backup.service
+BACKUP_TIMER=svmkit-backup.timer
+
+step::00::wait-for-a-stable-environment() {
+    cloud-init::wait-for-stable-environment
+}
+
+step::10::remove-timer() {
+    svmkit::sudo systemctl disable --now "${BACKUP_TIMER}" || true
+    svmkit::sudo systemctl stop "${BACKUP_SERVICE}" || true
+    svmkit::sudo rm -f /etc/systemd/system/"${BACKUP_TIMER}" /etc/systemd/system/"${BACKUP_SERVICE}"
+    svmkit::sudo systemctl daemon-reload
+}
+
+step::20::remove-backup-script() {
+    svmkit::sudo rm -f /usr/local/bin/svmkit-backup /etc/svmkit-backup.conf
+}
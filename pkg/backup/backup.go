@@ -0,0 +1,353 @@
+package backup
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/abklabs/svmkit/pkg/agave"
+	"github.com/abklabs/svmkit/pkg/machine/user"
+	"github.com/abklabs/svmkit/pkg/runner"
+	"github.com/abklabs/svmkit/pkg/runner/deb"
+)
+
+const (
+	ServiceName = "svmkit-backup"
+
+	defaultSchedule  = "hourly"
+	defaultRetention = 24
+)
+
+var (
+	bucketRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+	nameRegexp   = regexp.MustCompile(`^[0-9]{8}T[0-9]{6}Z$`)
+)
+
+// S3Destination stores backups in an S3 bucket.  EndpointURL points
+// at an S3-compatible service other than AWS, such as MinIO.
+type S3Destination struct {
+	Bucket          string  `pulumi:"bucket"`
+	Prefix          *string `pulumi:"prefix,optional"`
+	EndpointURL     *string `pulumi:"endpointURL,optional"`
+	Region          *string `pulumi:"region,optional"`
+	AccessKeyID     string  `pulumi:"accessKeyId" provider:"secret"`
+	SecretAccessKey string  `pulumi:"secretAccessKey" provider:"secret"`
+}
+
+func (s *S3Destination) Check() error {
+	if !bucketRegexp.MatchString(s.Bucket) {
+		return fmt.Errorf("invalid S3 bucket name '%s'", s.Bucket)
+	}
+
+	if u := s.EndpointURL; u != nil {
+		parsed, err := url.ParseRequestURI(*u)
+
+		if err != nil {
+			return fmt.Errorf("invalid S3 endpoint URL '%s': %w", *u, err)
+		}
+
+		if parsed.Scheme != "http" && parsed.Scheme != "https" {
+			return fmt.Errorf("S3 endpoint URL '%s' must be http or https", *u)
+		}
+	}
+
+	if s.AccessKeyID == "" || s.SecretAccessKey == "" {
+		return fmt.Errorf("the S3 destination needs an access key ID and secret access key")
+	}
+
+	return nil
+}
+
+// URL returns the s3:// URL backups are stored under.
+func (s *S3Destination) URL() string {
+	u := "s3://" + s.Bucket
+
+	if p := s.Prefix; p != nil {
+		if trimmed := strings.Trim(*p, "/"); trimmed != "" {
+			u += "/" + trimmed
+		}
+	}
+
+	return u
+}
+
+// Destination is where backups are stored: a directory on the host,
+// e.g. a mounted volume, or an S3 bucket.
+type Destination struct {
+	Path *string        `pulumi:"path,optional"`
+	S3   *S3Destination `pulumi:"s3,optional"`
+}
+
+func (d *Destination) Check() error {
+	if (d.Path == nil) == (d.S3 == nil) {
+		return fmt.Errorf("a backup destination needs exactly one of a path or an S3 bucket")
+	}
+
+	if p := d.Path; p != nil {
+		if !path.IsAbs(*p) || path.Clean(*p) == "/" {
+			return fmt.Errorf("invalid backup path '%s'", *p)
+		}
+	}
+
+	if s := d.S3; s != nil {
+		if err := s.Check(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *Destination) Env() *runner.EnvBuilder {
+	b := runner.NewEnvBuilder()
+
+	b.SetP("BACKUP_PATH", d.Path)
+
+	if s := d.S3; s != nil {
+		b.Set("BACKUP_S3_URL", s.URL())
+		b.SetP("BACKUP_S3_ENDPOINT_URL", s.EndpointURL)
+		b.SetP("AWS_DEFAULT_REGION", s.Region)
+		b.Set("AWS_ACCESS_KEY_ID", s.AccessKeyID)
+		b.Set("AWS_SECRET_ACCESS_KEY", s.SecretAccessKey)
+	}
+
+	return b
+}
+
+// Packages returns the packages the destination needs.  S3 is reached
+// with rclone, which, unlike the AWS CLI, every supported release
+// packages.
+func (d *Destination) Packages() []string {
+	if d.S3 != nil {
+		return []string{"rclone"}
+	}
+
+	return nil
+}
+
+// Backup periodically archives a validator's latest full and
+// incremental snapshots, along with its tower, to Destination.  The
+// ledger directories default to those of an agave validator run by
+// ServiceUser.
+type Backup struct {
+	runner.RunnerCommand
+
+	ServiceUser                    *user.ServiceUser `pulumi:"serviceUser,optional"`
+	LedgerPath                     *string           `pulumi:"ledgerPath,optional"`
+	FullSnapshotArchivePath        *string           `pulumi:"fullSnapshotArchivePath,optional"`
+	IncrementalSnapshotArchivePath *string           `pulumi:"incrementalSnapshotArchivePath,optional"`
+
+	Destination Destination `pulumi:"destination"`
+
+	// Schedule is when backups are taken, as a systemd OnCalendar
+	// expression.  Defaults to hourly.
+	Schedule *string `pulumi:"schedule,optional"`
+	// Retention is the number of backups kept at the destination.
+	// Defaults to 24.
+	Retention *int `pulumi:"retention,optional"`
+}
+
+func (b *Backup) Install() runner.Command {
+	return &InstallCommand{
+		Backup: *b,
+	}
+}
+
+func (b *Backup) Uninstall() runner.Command {
+	return &UninstallCommand{
+		Backup: *b,
+	}
+}
+
+func (b *Backup) Restore() runner.Command {
+	return &RestoreCommand{
+		Backup: *b,
+	}
+}
+
+func (b *Backup) check() error {
+	b.SetConfigDefaults()
+
+	if err := b.ServiceUser.Check(); err != nil {
+		return err
+	}
+
+	for _, p := range []*string{b.LedgerPath, b.FullSnapshotArchivePath, b.IncrementalSnapshotArchivePath} {
+		if p != nil && !path.IsAbs(*p) {
+			return fmt.Errorf("ledger and snapshot paths must be absolute, not '%s'", *p)
+		}
+	}
+
+	if err := b.Destination.Check(); err != nil {
+		return err
+	}
+
+	if s := b.Schedule; s != nil && strings.TrimSpace(*s) == "" {
+		return fmt.Errorf("the backup schedule can't be empty")
+	}
+
+	if r := b.Retention; r != nil && *r < 1 {
+		return fmt.Errorf("backup retention must be at least 1, not %d", *r)
+	}
+
+	grp := deb.Package{}.MakePackageGroup(b.Destination.Packages()...)
+
+	return b.UpdatePackageGroup(grp)
+}
+
+// config returns the configuration the backup script reads, for both
+// the timer's backups and restores.
+func (b *Backup) config() *runner.EnvBuilder {
+	e := runner.NewEnvBuilder()
+
+	ledger := agave.NewPaths(b.ServiceUser).Ledger
+	if p := b.LedgerPath; p != nil {
+		ledger = *p
+	}
+
+	full := ledger
+	if p := b.FullSnapshotArchivePath; p != nil {
+		full = *p
+	}
+
+	incremental := full
+	if p := b.IncrementalSnapshotArchivePath; p != nil {
+		incremental = *p
+	}
+
+	retention := defaultRetention
+	if r := b.Retention; r != nil {
+		retention = *r
+	}
+
+	e.Set("BACKUP_LEDGER_DIR", ledger)
+	e.Set("BACKUP_FULL_SNAPSHOT_DIR", full)
+	e.Set("BACKUP_INCREMENTAL_SNAPSHOT_DIR", incremental)
+	e.Set("BACKUP_OWNER", b.ServiceUser.GetName()+":"+b.ServiceUser.GetGroup())
+	e.SetInt("BACKUP_RETENTION", retention)
+	e.Merge(b.Destination.Env())
+
+	return e
+}
+
+func (b *Backup) addToPayload(p *runner.Payload, script string) error {
+	steps, err := assets.Open(script)
+
+	if err != nil {
+		return err
+	}
+
+	p.AddReader(runner.ScriptNameSteps, steps)
+
+	backup, err := assets.Open(assetsBackupScript)
+
+	if err != nil {
+		return err
+	}
+
+	p.AddReader(ServiceName, backup)
+	p.AddReader(ServiceName+".conf", b.config().Buffer())
+
+	return b.RunnerCommand.AddToPayload(p)
+}
+
+// InstallCommand installs the backup script and a systemd timer that
+// runs it on Schedule.
+type InstallCommand struct {
+	Backup
+}
+
+func (c *InstallCommand) Check() error {
+	return c.check()
+}
+
+func (c *InstallCommand) Env() *runner.EnvBuilder {
+	b := runner.NewEnvBuilder()
+
+	schedule := defaultSchedule
+	if s := c.Schedule; s != nil {
+		schedule = *s
+	}
+
+	b.Merge(c.ServiceUser.Env())
+	b.Set("BACKUP_SCHEDULE", schedule)
+	b.Merge(c.RunnerCommand.Env())
+
+	return b
+}
+
+func (c *InstallCommand) AddToPayload(p *runner.Payload) error {
+	return c.addToPayload(p, assetsInstallScript)
+}
+
+// UninstallCommand removes the timer and the backup script.  Backups
+// already taken are left at the destination.
+type UninstallCommand struct {
+	Backup
+}
+
+// Check only sets up the runner; removing the timer doesn't need the
+// destination, so a stack can be torn down after its credentials are
+// gone.
+func (c *UninstallCommand) Check() error {
+	c.SetConfigDefaults()
+
+	return c.UpdatePackageGroup(deb.Package{}.MakePackageGroup())
+}
+
+func (c *UninstallCommand) Env() *runner.EnvBuilder {
+	return c.RunnerCommand.Env()
+}
+
+func (c *UninstallCommand) AddToPayload(p *runner.Payload) error {
+	steps, err := assets.Open(assetsUninstallScript)
+
+	if err != nil {
+		return err
+	}
+
+	p.AddReader(runner.ScriptNameSteps, steps)
+
+	return c.RunnerCommand.AddToPayload(p)
+}
+
+// RestoreCommand stages a backup in the ledger directories, so that
+// the validator starts from it.  It is meant to be run before the
+// validator is first started, and refuses to run while
+// ValidatorService is active.  A ledger that already has a full
+// snapshot is left alone unless Force is set.
+type RestoreCommand struct {
+	Backup
+
+	// Name is the backup to restore, e.g. "20240102T030405Z".
+	// Defaults to the latest.
+	Name             *string `pulumi:"name,optional"`
+	ValidatorService *string `pulumi:"validatorService,optional"`
+	Force            *bool   `pulumi:"force,optional"`
+}
+
+func (c *RestoreCommand) Check() error {
+	if n := c.Name; n != nil && !nameRegexp.MatchString(*n) {
+		return fmt.Errorf("invalid backup name '%s'", *n)
+	}
+
+	return c.check()
+}
+
+func (c *RestoreCommand) Env() *runner.EnvBuilder {
+	b := runner.NewEnvBuilder()
+
+	b.Merge(c.ServiceUser.Env())
+	b.SetP("RESTORE_NAME", c.Name)
+	b.SetP("VALIDATOR_SERVICE", c.ValidatorService)
+	b.SetBoolP("RESTORE_FORCE", c.Force)
+	b.Merge(c.RunnerCommand.Env())
+
+	return b
+}
+
+func (c *RestoreCommand) AddToPayload(p *runner.Payload) error {
+	return c.addToPayload(p, assetsRestoreScript)
+}
@@ -0,0 +1,137 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/abklabs/svmkit/pkg/runner"
+	"github.com/abklabs/svmkit/pkg/runner/runnertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ptr[T any](in T) *T {
+	return &in
+}
+
+func TestDestinationCheck(t *testing.T) {
+	s3 := S3Destination{Bucket: "ledger-backups", AccessKeyID: "minio", SecretAccessKey: "minio123"}
+
+	assert.NoError(t, (&Destination{Path: ptr("/mnt/backups")}).Check())
+	assert.NoError(t, (&Destination{S3: &s3}).Check())
+
+	assert.Error(t, (&Destination{}).Check())
+	assert.Error(t, (&Destination{Path: ptr("/mnt/backups"), S3: &s3}).Check())
+	assert.Error(t, (&Destination{Path: ptr("backups")}).Check())
+	assert.Error(t, (&Destination{Path: ptr("/")}).Check())
+
+	s3.EndpointURL = ptr("http://127.0.0.1:9000")
+	assert.NoError(t, s3.Check())
+
+	s3.EndpointURL = ptr("minio:9000")
+	assert.Error(t, s3.Check())
+
+	s3.EndpointURL = nil
+	s3.Bucket = "Bad_Bucket"
+	assert.Error(t, s3.Check())
+
+	s3.Bucket = "ledger-backups"
+	s3.AccessKeyID = ""
+	assert.Error(t, s3.Check())
+}
+
+func TestBackupCheck(t *testing.T) {
+	b := Backup{}
+	assert.Error(t, b.Install().Check())
+
+	b.Destination.Path = ptr("/mnt/backups")
+	assert.NoError(t, b.Install().Check())
+
+	b.Retention = ptr(0)
+	assert.Error(t, b.Install().Check())
+	b.Retention = nil
+
+	b.Schedule = ptr(" ")
+	assert.Error(t, b.Install().Check())
+	b.Schedule = nil
+
+	b.LedgerPath = ptr("ledger")
+	assert.Error(t, b.Install().Check())
+	b.LedgerPath = nil
+
+	r := &RestoreCommand{Backup: b, Name: ptr("latest")}
+	assert.Error(t, r.Check())
+
+	r.Name = ptr("20240102T030405Z")
+	assert.NoError(t, r.Check())
+
+	// Uninstalling doesn't reach the destination.
+	b.Destination = Destination{S3: &S3Destination{Bucket: "svmkit-backups"}}
+	assert.Error(t, b.Install().Check())
+	assert.NoError(t, b.Uninstall().Check())
+	assert.NoError(t, (&Backup{}).Uninstall().Check())
+}
+
+func TestBackupConfig(t *testing.T) {
+	b := Backup{
+		Destination: Destination{S3: &S3Destination{
+			Bucket:          "ledger-backups",
+			Prefix:          ptr("/validators/one/"),
+			EndpointURL:     ptr("http://127.0.0.1:9000"),
+			AccessKeyID:     "minio",
+			SecretAccessKey: "minio123",
+		}},
+		FullSnapshotArchivePath: ptr("/mnt/snapshots"),
+	}
+
+	env := b.config().Map()
+
+	assert.Equal(t, "/home/sol/ledger", env["BACKUP_LEDGER_DIR"])
+	assert.Equal(t, "/mnt/snapshots", env["BACKUP_FULL_SNAPSHOT_DIR"])
+	assert.Equal(t, "/mnt/snapshots", env["BACKUP_INCREMENTAL_SNAPSHOT_DIR"])
+	assert.Equal(t, "sol:sol", env["BACKUP_OWNER"])
+	assert.Equal(t, "24", env["BACKUP_RETENTION"])
+	assert.Equal(t, "s3://ledger-backups/validators/one", env["BACKUP_S3_URL"])
+	assert.Equal(t, "http://127.0.0.1:9000", env["BACKUP_S3_ENDPOINT_URL"])
+	assert.Equal(t, "minio", env["AWS_ACCESS_KEY_ID"])
+	assert.NotContains(t, env, "BACKUP_PATH")
+}
+
+func TestInstallPayload(t *testing.T) {
+	b := Backup{Destination: Destination{S3: &S3Destination{Bucket: "ledger-backups", AccessKeyID: "minio", SecretAccessKey: "minio123"}}}
+
+	cmd := b.Install()
+	require.NoError(t, cmd.Check())
+
+	files := runnertest.PayloadFiles(t, cmd)
+
+	assert.Contains(t, files, ServiceName)
+	assert.Contains(t, files[ServiceName+".conf"], "AWS_SECRET_ACCESS_KEY=minio123")
+
+	install, err := assets.ReadFile(assetsInstallScript)
+	require.NoError(t, err)
+	assert.Equal(t, string(install), files[runner.ScriptNameSteps])
+
+	env := cmd.Env().Map()
+	assert.Equal(t, defaultSchedule, env["BACKUP_SCHEDULE"])
+	assert.Equal(t, "(rclone)", env["PACKAGE_LIST"])
+	assert.NotContains(t, env, "AWS_SECRET_ACCESS_KEY")
+}
+
+func TestRestorePayload(t *testing.T) {
+	b := Backup{Destination: Destination{Path: ptr("/mnt/backups")}}
+
+	cmd := &RestoreCommand{Backup: b, ValidatorService: ptr("svmkit-agave-validator"), Force: ptr(true)}
+	require.NoError(t, cmd.Check())
+
+	files := runnertest.PayloadFiles(t, cmd)
+
+	restore, err := assets.ReadFile(assetsRestoreScript)
+	require.NoError(t, err)
+	assert.Equal(t, string(restore), files[runner.ScriptNameSteps])
+	assert.Contains(t, files[ServiceName+".conf"], "BACKUP_PATH=/mnt/backups")
+
+	env := cmd.Env().Map()
+	assert.Equal(t, "svmkit-agave-validator", env["VALIDATOR_SERVICE"])
+	assert.Equal(t, "true", env["RESTORE_FORCE"])
+	assert.Equal(t, "()", env["PACKAGE_LIST"])
+}
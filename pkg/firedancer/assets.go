@@ -2,6 +2,7 @@ package firedancer
 
 import (
 	"embed"
+	"text/template"
 )
 
 //go:embed assets
@@ -10,6 +11,10 @@ var assets embed.FS
 const (
 	assetsInstall        = "assets/install"
 	assetsUninstall      = "assets/uninstall"
-	assetsFDService      = "assets/svmkit-fd.service"
-	assetsFDSetupService = "assets/svmkit-fd-setup.service"
+	assetsUpdate         = "assets/update"
+	assetsFDService      = "assets/svmkit-fd.service.tmpl"
+	assetsFDSetupService = "assets/svmkit-fd-setup.service.tmpl"
 )
+
+var fdServiceTmpl = template.Must(template.ParseFS(assets, assetsFDService))
+var fdSetupServiceTmpl = template.Must(template.ParseFS(assets, assetsFDSetupService))
@@ -1,6 +1,8 @@
 package firedancer
 
 import (
+	"bytes"
+	"fmt"
 	"io"
 
 	"github.com/BurntSushi/toml"
@@ -25,6 +27,9 @@ type ConfigLedger struct {
 	AccountIndexes          *[]string `toml:"account_indexes,omitempty" pulumi:"accountIndexes,optional"`
 	AccountIndexExcludeKeys *[]string `toml:"account_index_exclude_keys,omitempty" pulumi:"accountIndexExcludeKeys,optional"`
 	AccountIndexIncludeKeys *[]string `toml:"account_index_include_keys,omitempty" pulumi:"accountIndexIncludeKeys,optional"`
+	AccountsIndexPath       *string   `toml:"accounts_index_path,omitempty" pulumi:"accountsIndexPath,optional"`
+	AccountsHashCachePath   *string   `toml:"accounts_hash_cache_path,omitempty" pulumi:"accountsHashCachePath,optional"`
+	EnableAccountsDiskIndex *bool     `toml:"enable_accounts_disk_index" pulumi:"enableAccountsDiskIndex,optional"`
 	SnapshotArchiveFormat   *string   `toml:"snapshot_archive_format,omitempty" pulumi:"snapshotArchiveFormat,optional"`
 	RequireTower            *bool     `toml:"require_tower" pulumi:"requireTower,optional"`
 }
@@ -37,18 +42,21 @@ type ConfigGossip struct {
 }
 
 type ConfigRPC struct {
-	Port                          *int  `toml:"port,omitempty" pulumi:"port,optional"`
-	FullAPI                       *bool `toml:"full_api" pulumi:"fullApi,optional"`
-	Private                       *bool `toml:"private" pulumi:"private,optional"`
-	TransactionHistory            *bool `toml:"transaction_history" pulumi:"transactionHistory,optional"`
-	ExtendedTxMetadataStorage     *bool `toml:"extended_tx_metadata_storage" pulumi:"extendedTxMetadataStorage,optional"`
-	OnlyKnown                     *bool `toml:"only_known" pulumi:"onlyKnown,optional"`
-	PubsubEnableBlockSubscription *bool `toml:"pubsub_enable_block_subscription" pulumi:"pubsubEnableBlockSubscription,optional"`
-	PubsubEnableVoteSubscription  *bool `toml:"pubsub_enable_vote_subscription" pulumi:"pubsubEnableVoteSubscription,optional"`
-	BigtableLedgerStorage         *bool `toml:"bigtable_ledger_storage" pulumi:"bigtableLedgerStorage,optional"`
+	Port                          *int    `toml:"port,omitempty" pulumi:"port,optional"`
+	FullAPI                       *bool   `toml:"full_api" pulumi:"fullApi,optional"`
+	Private                       *bool   `toml:"private" pulumi:"private,optional"`
+	BindAddress                   *string `toml:"bind_address,omitempty" pulumi:"bindAddress,optional"`
+	PublicAddress                 *string `toml:"public_address,omitempty" pulumi:"publicAddress,optional"`
+	TransactionHistory            *bool   `toml:"transaction_history" pulumi:"transactionHistory,optional"`
+	ExtendedTxMetadataStorage     *bool   `toml:"extended_tx_metadata_storage" pulumi:"extendedTxMetadataStorage,optional"`
+	OnlyKnown                     *bool   `toml:"only_known" pulumi:"onlyKnown,optional"`
+	PubsubEnableBlockSubscription *bool   `toml:"pubsub_enable_block_subscription" pulumi:"pubsubEnableBlockSubscription,optional"`
+	PubsubEnableVoteSubscription  *bool   `toml:"pubsub_enable_vote_subscription" pulumi:"pubsubEnableVoteSubscription,optional"`
+	BigtableLedgerStorage         *bool   `toml:"bigtable_ledger_storage" pulumi:"bigtableLedgerStorage,optional"`
 }
 
 type ConfigSnapshots struct {
+	Enabled                             *bool   `toml:"enabled" pulumi:"enabled,optional"`
 	IncrementalSnapshots                *bool   `toml:"incremental_snapshots" pulumi:"incrementalSnapshots,optional"`
 	FullSnapshotIntervalSlots           *int    `toml:"full_snapshot_interval_slots,omitempty" pulumi:"fullSnapshotIntervalSlots,optional"`
 	IncrementalSnapshotIntervalSlots    *int    `toml:"incremental_snapshot_interval_slots,omitempty" pulumi:"incrementalSnapshotIntervalSlots,optional"`
@@ -77,51 +85,303 @@ type ConfigConsensus struct {
 }
 
 type ConfigLayout struct {
-	Affinity        *string `toml:"affinity,omitempty" pulumi:"affinity,optional"`
-	AgaveAffinity   *string `toml:"agave_affinity,omitempty" pulumi:"agaveAffinity,optional"`
-	NetTileCount    *int    `toml:"net_tile_count,omitempty" pulumi:"netTileCount,optional"`
-	QuicTileCount   *int    `toml:"quic_tile_count,omitempty" pulumi:"quicTileCount,optional"`
-	ResolvTileCount *int    `toml:"resolv_tile_count,omitempty" pulumi:"resolvTileCount,optional"`
-	VerifyTileCount *int    `toml:"verify_tile_count,omitempty" pulumi:"verifyTileCount,optional"`
-	BankTileCount   *int    `toml:"bank_tile_count,omitempty" pulumi:"bankTileCount,optional"`
-	ShredTileCount  *int    `toml:"shred_tile_count,omitempty" pulumi:"shredTileCount,optional"`
+	Affinity                            *string `toml:"affinity,omitempty" pulumi:"affinity,optional"`
+	AgaveAffinity                       *string `toml:"agave_affinity,omitempty" pulumi:"agaveAffinity,optional"`
+	AgaveUnifiedSchedulerHandlerThreads *int    `toml:"agave_unified_scheduler_handler_threads,omitempty" pulumi:"agaveUnifiedSchedulerHandlerThreads,optional"`
+	ExecTileCount                       *int    `toml:"exec_tile_count,omitempty" pulumi:"execTileCount,optional"`
+	WriterTileCount                     *int    `toml:"writer_tile_count,omitempty" pulumi:"writerTileCount,optional"`
+	NetTileCount                        *int    `toml:"net_tile_count,omitempty" pulumi:"netTileCount,optional"`
+	QuicTileCount                       *int    `toml:"quic_tile_count,omitempty" pulumi:"quicTileCount,optional"`
+	ResolvTileCount                     *int    `toml:"resolv_tile_count,omitempty" pulumi:"resolvTileCount,optional"`
+	VerifyTileCount                     *int    `toml:"verify_tile_count,omitempty" pulumi:"verifyTileCount,optional"`
+	BankTileCount                       *int    `toml:"bank_tile_count,omitempty" pulumi:"bankTileCount,optional"`
+	ShredTileCount                      *int    `toml:"shred_tile_count,omitempty" pulumi:"shredTileCount,optional"`
 }
 
 type ConfigHugeTLBFS struct {
 	MountPath *string `toml:"mount_path,omitempty" pulumi:"mountPath,optional"`
 }
 
+// ConfigNet configures how the validator sends and receives packets,
+// as of the 0.5 releases.  Earlier releases configure the net tile in
+// [tiles.net] instead, which only ExtraConfig can set.
+type ConfigNet struct {
+	Interface   *string          `toml:"interface,omitempty" pulumi:"interface,optional"`
+	Provider    *string          `toml:"provider,omitempty" pulumi:"provider,optional"`
+	BindAddress *string          `toml:"bind_address,omitempty" pulumi:"bindAddress,optional"`
+	XDP         *ConfigNetXDP    `toml:"xdp,omitempty" pulumi:"xdp,optional"`
+	Socket      *ConfigNetSocket `toml:"socket,omitempty" pulumi:"socket,optional"`
+}
+
+type ConfigNetXDP struct {
+	XDPMode            *string `toml:"xdp_mode,omitempty" pulumi:"xdpMode,optional"`
+	XDPZeroCopy        *bool   `toml:"xdp_zero_copy" pulumi:"xdpZeroCopy,optional"`
+	XDPRxQueueSize     *int    `toml:"xdp_rx_queue_size,omitempty" pulumi:"xdpRxQueueSize,optional"`
+	XDPTxQueueSize     *int    `toml:"xdp_tx_queue_size,omitempty" pulumi:"xdpTxQueueSize,optional"`
+	FlushTimeoutMicros *int    `toml:"flush_timeout_micros,omitempty" pulumi:"flushTimeoutMicros,optional"`
+}
+
+type ConfigNetSocket struct {
+	ReceiveBufferSize *int `toml:"receive_buffer_size,omitempty" pulumi:"receiveBufferSize,optional"`
+	SendBufferSize    *int `toml:"send_buffer_size,omitempty" pulumi:"sendBufferSize,optional"`
+}
+
+type ConfigTiles struct {
+	Netlink *ConfigTilesNetlink `toml:"netlink,omitempty" pulumi:"netlink,optional"`
+	Quic    *ConfigTilesQuic    `toml:"quic,omitempty" pulumi:"quic,optional"`
+	Verify  *ConfigTilesVerify  `toml:"verify,omitempty" pulumi:"verify,optional"`
+	Dedup   *ConfigTilesDedup   `toml:"dedup,omitempty" pulumi:"dedup,optional"`
+	Bundle  *ConfigTilesBundle  `toml:"bundle,omitempty" pulumi:"bundle,optional"`
+	Pack    *ConfigTilesPack    `toml:"pack,omitempty" pulumi:"pack,optional"`
+	Poh     *ConfigTilesPoh     `toml:"poh,omitempty" pulumi:"poh,optional"`
+	Shred   *ConfigTilesShred   `toml:"shred,omitempty" pulumi:"shred,optional"`
+	Metric  *ConfigTilesMetric  `toml:"metric,omitempty" pulumi:"metric,optional"`
+	Gui     *ConfigTilesGui     `toml:"gui,omitempty" pulumi:"gui,optional"`
+}
+
+type ConfigTilesNetlink struct {
+	MaxRoutes    *int `toml:"max_routes,omitempty" pulumi:"maxRoutes,optional"`
+	MaxNeighbors *int `toml:"max_neighbors,omitempty" pulumi:"maxNeighbors,optional"`
+}
+
+type ConfigTilesQuic struct {
+	RegularTransactionListenPort *int  `toml:"regular_transaction_listen_port,omitempty" pulumi:"regularTransactionListenPort,optional"`
+	QuicTransactionListenPort    *int  `toml:"quic_transaction_listen_port,omitempty" pulumi:"quicTransactionListenPort,optional"`
+	TxnReassemblyCount           *int  `toml:"txn_reassembly_count,omitempty" pulumi:"txnReassemblyCount,optional"`
+	MaxConcurrentConnections     *int  `toml:"max_concurrent_connections,omitempty" pulumi:"maxConcurrentConnections,optional"`
+	MaxConcurrentHandshakes      *int  `toml:"max_concurrent_handshakes,omitempty" pulumi:"maxConcurrentHandshakes,optional"`
+	IdleTimeoutMillis            *int  `toml:"idle_timeout_millis,omitempty" pulumi:"idleTimeoutMillis,optional"`
+	AckDelayMillis               *int  `toml:"ack_delay_millis,omitempty" pulumi:"ackDelayMillis,optional"`
+	Retry                        *bool `toml:"retry" pulumi:"retry,optional"`
+}
+
+type ConfigTilesVerify struct {
+	SignatureCacheSize *int `toml:"signature_cache_size,omitempty" pulumi:"signatureCacheSize,optional"`
+	ReceiveBufferSize  *int `toml:"receive_buffer_size,omitempty" pulumi:"receiveBufferSize,optional"`
+	MTU                *int `toml:"mtu,omitempty" pulumi:"mtu,optional"`
+}
+
+type ConfigTilesDedup struct {
+	SignatureCacheSize *int `toml:"signature_cache_size,omitempty" pulumi:"signatureCacheSize,optional"`
+}
+
+// ConfigTilesBundle connects the validator to a block engine, such as
+// Jito's, to receive bundles from.
+type ConfigTilesBundle struct {
+	Enabled                    *bool   `toml:"enabled" pulumi:"enabled,optional"`
+	URL                        *string `toml:"url,omitempty" pulumi:"url,optional"`
+	TLSDomainName              *string `toml:"tls_domain_name,omitempty" pulumi:"tlsDomainName,optional"`
+	TLSCertVerify              *bool   `toml:"tls_cert_verify" pulumi:"tlsCertVerify,optional"`
+	TipDistributionProgramAddr *string `toml:"tip_distribution_program_addr,omitempty" pulumi:"tipDistributionProgramAddr,optional"`
+	TipPaymentProgramAddr      *string `toml:"tip_payment_program_addr,omitempty" pulumi:"tipPaymentProgramAddr,optional"`
+	TipDistributionAuthority   *string `toml:"tip_distribution_authority,omitempty" pulumi:"tipDistributionAuthority,optional"`
+	CommissionBps              *int    `toml:"commission_bps,omitempty" pulumi:"commissionBps,optional"`
+	KeepaliveIntervalMillis    *int    `toml:"keepalive_interval_millis,omitempty" pulumi:"keepaliveIntervalMillis,optional"`
+}
+
+type ConfigTilesPack struct {
+	MaxPendingTransactions *int    `toml:"max_pending_transactions,omitempty" pulumi:"maxPendingTransactions,optional"`
+	UseConsumedCus         *bool   `toml:"use_consumed_cus" pulumi:"useConsumedCus,optional"`
+	ScheduleStrategy       *string `toml:"schedule_strategy,omitempty" pulumi:"scheduleStrategy,optional"`
+}
+
+type ConfigTilesPoh struct {
+	LaggedConsecutiveLeaderStart *bool `toml:"lagged_consecutive_leader_start" pulumi:"laggedConsecutiveLeaderStart,optional"`
+}
+
+type ConfigTilesShred struct {
+	MaxPendingShredSets                   *int      `toml:"max_pending_shred_sets,omitempty" pulumi:"maxPendingShredSets,optional"`
+	ShredListenPort                       *int      `toml:"shred_listen_port,omitempty" pulumi:"shredListenPort,optional"`
+	AdditionalShredDestinationsRetransmit *[]string `toml:"additional_shred_destinations_retransmit,omitempty" pulumi:"additionalShredDestinationsRetransmit,optional"`
+	AdditionalShredDestinationsLeader     *[]string `toml:"additional_shred_destinations_leader,omitempty" pulumi:"additionalShredDestinationsLeader,optional"`
+}
+
+// ConfigTilesMetric configures the metric tile, which serves the
+// validator's metrics to Prometheus.  It listens on 0.0.0.0:7999
+// unless told otherwise.
+type ConfigTilesMetric struct {
+	PrometheusListenAddress *string `toml:"prometheus_listen_address,omitempty" pulumi:"prometheusListenAddress,optional"`
+	PrometheusListenPort    *int    `toml:"prometheus_listen_port,omitempty" pulumi:"prometheusListenPort,optional"`
+}
+
+// ConfigTilesGui configures the gui tile, which serves the validator's
+// web dashboard.  It is enabled, and listens on 127.0.0.1:80, unless
+// told otherwise.
+type ConfigTilesGui struct {
+	Enabled                 *bool   `toml:"enabled" pulumi:"enabled,optional"`
+	GuiListenAddress        *string `toml:"gui_listen_address,omitempty" pulumi:"guiListenAddress,optional"`
+	GuiListenPort           *int    `toml:"gui_listen_port,omitempty" pulumi:"guiListenPort,optional"`
+	MaxHttpConnections      *int    `toml:"max_http_connections,omitempty" pulumi:"maxHttpConnections,optional"`
+	MaxWebsocketConnections *int    `toml:"max_websocket_connections,omitempty" pulumi:"maxWebsocketConnections,optional"`
+	MaxHttpRequestLength    *int    `toml:"max_http_request_length,omitempty" pulumi:"maxHttpRequestLength,optional"`
+	SendBufferSizeMb        *int    `toml:"send_buffer_size_mb,omitempty" pulumi:"sendBufferSizeMb,optional"`
+}
+
+// ConfigDevelopment holds settings meant for development clusters,
+// which no mainnet validator should need.
+type ConfigDevelopment struct {
+	Sandbox   *bool                     `toml:"sandbox" pulumi:"sandbox,optional"`
+	NoClone   *bool                     `toml:"no_clone" pulumi:"noClone,optional"`
+	CoreDump  *bool                     `toml:"core_dump" pulumi:"coreDump,optional"`
+	NoAgave   *bool                     `toml:"no_agave" pulumi:"noAgave,optional"`
+	Bootstrap *bool                     `toml:"bootstrap" pulumi:"bootstrap,optional"`
+	Gossip    *ConfigDevelopmentGossip  `toml:"gossip,omitempty" pulumi:"gossip,optional"`
+	Genesis   *ConfigDevelopmentGenesis `toml:"genesis,omitempty" pulumi:"genesis,optional"`
+}
+
+type ConfigDevelopmentGossip struct {
+	AllowPrivateAddress *bool `toml:"allow_private_address" pulumi:"allowPrivateAddress,optional"`
+}
+
+type ConfigDevelopmentGenesis struct {
+	HashesPerTick             *int  `toml:"hashes_per_tick,omitempty" pulumi:"hashesPerTick,optional"`
+	TargetTickDurationMicros  *int  `toml:"target_tick_duration_micros,omitempty" pulumi:"targetTickDurationMicros,optional"`
+	TicksPerSlot              *int  `toml:"ticks_per_slot,omitempty" pulumi:"ticksPerSlot,optional"`
+	FundInitialAccounts       *int  `toml:"fund_initial_accounts,omitempty" pulumi:"fundInitialAccounts,optional"`
+	FundInitialAmountLamports *int  `toml:"fund_initial_amount_lamports,omitempty" pulumi:"fundInitialAmountLamports,optional"`
+	VoteAccountStakeLamports  *int  `toml:"vote_account_stake_lamports,omitempty" pulumi:"voteAccountStakeLamports,optional"`
+	WarmupEpochs              *bool `toml:"warmup_epochs" pulumi:"warmupEpochs,optional"`
+}
+
 type Config struct {
+	// Release picks the schema the config is checked against.  It
+	// defaults to the release of the installed version, or the
+	// latest release.
+	Release *Release `toml:"-" pulumi:"release,optional"`
+
 	Name             *string `toml:"name,omitempty" pulumi:"name,optional"`
 	User             *string `toml:"user,omitempty" pulumi:"user,optional"`
 	ScratchDirectory *string `toml:"scratch_directory,omitempty" pulumi:"scratchDirectory,optional"`
 	DynamicPortRange *string `toml:"dynamic_port_range,omitempty" pulumi:"dynamicPortRange,optional"`
 
-	Log       *ConfigLog       `toml:"log,omitempty" pulumi:"log,optional"`
-	Reporting *ConfigReporting `toml:"reporting,omitempty" pulumi:"reporting,optional"`
-	Ledger    *ConfigLedger    `toml:"ledger,omitempty" pulumi:"ledger,optional"`
-	Gossip    *ConfigGossip    `toml:"gossip,omitempty" pulumi:"gossip,optional"`
-	RPC       *ConfigRPC       `toml:"rpc,omitempty" pulumi:"rpc,optional"`
-	Snapshots *ConfigSnapshots `toml:"snapshots,omitempty" pulumi:"snapshots,optional"`
-	Consensus *ConfigConsensus `toml:"consensus,omitempty" pulumi:"consensus,optional"`
-	Layout    *ConfigLayout    `toml:"layout,omitempty" pulumi:"layout,optional"`
-	HugeTLBFS *ConfigHugeTLBFS `toml:"hugetlbfs,omitempty" pulumi:"hugetlbfs,optional"`
+	Log         *ConfigLog         `toml:"log,omitempty" pulumi:"log,optional"`
+	Reporting   *ConfigReporting   `toml:"reporting,omitempty" pulumi:"reporting,optional"`
+	Ledger      *ConfigLedger      `toml:"ledger,omitempty" pulumi:"ledger,optional"`
+	Gossip      *ConfigGossip      `toml:"gossip,omitempty" pulumi:"gossip,optional"`
+	RPC         *ConfigRPC         `toml:"rpc,omitempty" pulumi:"rpc,optional"`
+	Snapshots   *ConfigSnapshots   `toml:"snapshots,omitempty" pulumi:"snapshots,optional"`
+	Consensus   *ConfigConsensus   `toml:"consensus,omitempty" pulumi:"consensus,optional"`
+	Layout      *ConfigLayout      `toml:"layout,omitempty" pulumi:"layout,optional"`
+	HugeTLBFS   *ConfigHugeTLBFS   `toml:"hugetlbfs,omitempty" pulumi:"hugetlbfs,optional"`
+	Net         *ConfigNet         `toml:"net,omitempty" pulumi:"net,optional"`
+	Tiles       *ConfigTiles       `toml:"tiles,omitempty" pulumi:"tiles,optional"`
+	Development *ConfigDevelopment `toml:"development,omitempty" pulumi:"development,optional"`
 
+	// ExtraConfig holds TOML documents for settings without a typed
+	// field.  They are merged into the config, and may neither set
+	// a key twice nor set one the release doesn't know.
 	ExtraConfig *[]string `toml:"-" pulumi:"extraConfig,optional"`
 }
 
-func (c *Config) Encode(w io.Writer) error {
-	if err := toml.NewEncoder(w).Encode(c); err != nil {
-		return err
+func (c *Config) GetRelease() Release {
+	if c.Release == nil {
+		return ReleaseLatest
+	}
+
+	return *c.Release
+}
+
+// Check merges the config with its ExtraConfig, and checks the result
+// against the release's schema.
+func (c *Config) Check() error {
+	_, err := c.merged("")
+
+	return err
+}
+
+// CheckVariant is Check, but also rejects the keys only the other
+// variant accepts.
+func (c *Config) CheckVariant(variant Variant) error {
+	_, err := c.merged(variant)
+
+	return err
+}
+
+// merged returns the config as a TOML tree, with ExtraConfig merged
+// in, checked for variant.
+func (c *Config) merged(variant Variant) (map[string]any, error) {
+	release := c.GetRelease()
+
+	if err := release.Check(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	if err := toml.NewEncoder(&buf).Encode(c); err != nil {
+		return nil, err
+	}
+
+	tree := map[string]any{}
+
+	if _, err := toml.NewDecoder(&buf).Decode(&tree); err != nil {
+		return nil, err
 	}
 
 	if c.ExtraConfig != nil {
-		for _, v := range *c.ExtraConfig {
-			if _, err := w.Write([]byte(v)); err != nil {
-				return err
+		for i, v := range *c.ExtraConfig {
+			extra := map[string]any{}
+
+			if _, err := toml.Decode(v, &extra); err != nil {
+				return nil, fmt.Errorf("invalid firedancer extra config %d: %w", i, err)
+			}
+
+			if err := mergeTree("", tree, extra); err != nil {
+				return nil, fmt.Errorf("firedancer extra config %d: %w", i, err)
 			}
 		}
 	}
 
+	if err := release.checkKeys(tree, variant); err != nil {
+		return nil, err
+	}
+
+	if _, err := metricEndpoint(tree); err != nil {
+		return nil, err
+	}
+
+	if _, err := guiEndpoint(tree); err != nil {
+		return nil, err
+	}
+
+	return tree, nil
+}
+
+// mergeTree merges src into dst, refusing to replace any value.
+func mergeTree(prefix string, dst, src map[string]any) error {
+	for k, v := range src {
+		path := prefix + k
+
+		existing, ok := dst[k]
+
+		if !ok {
+			dst[k] = v
+			continue
+		}
+
+		d, dok := existing.(map[string]any)
+		s, sok := v.(map[string]any)
+
+		if !dok || !sok {
+			return fmt.Errorf("'%s' is already set", path)
+		}
+
+		if err := mergeTree(path+".", d, s); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
+
+// Encode writes the config, with ExtraConfig merged in, as TOML.  The
+// merged tree is written with its keys sorted, not in field order.
+func (c *Config) Encode(w io.Writer) error {
+	tree, err := c.merged("")
+
+	if err != nil {
+		return err
+	}
+
+	return toml.NewEncoder(w).Encode(tree)
+}
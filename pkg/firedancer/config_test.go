@@ -2,9 +2,14 @@ package firedancer
 
 import (
 	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/BurntSushi/toml"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func ptr[T any](in T) *T {
@@ -12,90 +17,90 @@ func ptr[T any](in T) *T {
 }
 
 func TestConfigEncode(t *testing.T) {
-	expectedTOML := `name = "fd1"
-user = "sol"
+	expectedTOML := `dynamic_port_range = "8000-9000"
+name = "fd1"
 scratch_directory = "/tmp/scratch"
-dynamic_port_range = "8000-9000"
+user = "sol"
 
-[log]
-  path = "/var/log/test.log"
-  colorize = "auto"
-  level_logfile = "info"
-  level_stderr = "warn"
-  level_flush = "error"
+[consensus]
+  authorized_voter_paths = ["/var/voter1", "/var/voter2"]
+  expected_bank_hash = "bankhash123"
+  expected_genesis_hash = "hash123"
+  expected_shred_version = 1
+  genesis_fetch = false
+  hard_fork_at_slots = ["slot1", "slot2"]
+  identity_path = "/var/identity"
+  known_validators = ["validator1", "validator2"]
+  os_network_limits_test = false
+  poh_speed_test = true
+  snapshot_fetch = true
+  vote_account_path = "/var/vote_account"
+  wait_for_supermajority_at_slot = 1000
+  wait_for_vote_to_start_leader = true
 
-[reporting]
-  solana_metrics_config = "http://metrics.solana.com"
+[development]
+  [development.gossip]
+    allow_private_address = true
+
+[gossip]
+  entrypoints = ["entry1", "entry2"]
+  host = "localhost"
+  port = 8080
+  port_check = true
+
+[hugetlbfs]
+  mount_path = "/mnt/hugetlbfs"
+
+[layout]
+  affinity = "affinity1"
+  agave_affinity = "agave1"
+  bank_tile_count = 2
+  net_tile_count = 10
+  quic_tile_count = 5
+  resolv_tile_count = 3
+  shred_tile_count = 4
+  verify_tile_count = 7
 
 [ledger]
-  path = "/home/sol/ledger"
-  accounts_path = "/home/sol/accounts"
-  limit_size = 1000
-  account_indexes = ["index1", "index2"]
   account_index_exclude_keys = ["key1", "key2"]
   account_index_include_keys = ["key3", "key4"]
-  snapshot_archive_format = "tar"
+  account_indexes = ["index1", "index2"]
+  accounts_path = "/home/sol/accounts"
+  limit_size = 1000
+  path = "/home/sol/ledger"
   require_tower = true
+  snapshot_archive_format = "tar"
 
-[gossip]
-  entrypoints = ["entry1", "entry2"]
-  port_check = true
-  port = 8080
-  host = "localhost"
+[log]
+  colorize = "auto"
+  level_flush = "error"
+  level_logfile = "info"
+  level_stderr = "warn"
+  path = "/var/log/test.log"
+
+[reporting]
+  solana_metrics_config = "http://metrics.solana.com"
 
 [rpc]
-  port = 8899
-  full_api = true
-  private = false
-  transaction_history = true
+  bigtable_ledger_storage = false
   extended_tx_metadata_storage = false
+  full_api = true
   only_known = true
+  port = 8899
+  private = false
   pubsub_enable_block_subscription = true
   pubsub_enable_vote_subscription = false
-  bigtable_ledger_storage = false
+  transaction_history = true
 
 [snapshots]
-  incremental_snapshots = true
   full_snapshot_interval_slots = 100
+  incremental_path = "/var/incremental_snapshots"
   incremental_snapshot_interval_slots = 50
+  incremental_snapshots = true
   maximum_full_snapshots_to_retain = 5
   maximum_incremental_snapshots_to_retain = 10
   minimum_snapshot_download_speed = 100
   path = "/var/snapshots"
-  incremental_path = "/var/incremental_snapshots"
-
-[consensus]
-  identity_path = "/var/identity"
-  vote_account_path = "/var/vote_account"
-  authorized_voter_paths = ["/var/voter1", "/var/voter2"]
-  snapshot_fetch = true
-  genesis_fetch = false
-  poh_speed_test = true
-  expected_genesis_hash = "hash123"
-  wait_for_supermajority_at_slot = 1000
-  expected_bank_hash = "bankhash123"
-  expected_shred_version = 1
-  wait_for_vote_to_start_leader = true
-  os_network_limits_test = false
-  hard_fork_at_slots = ["slot1", "slot2"]
-  known_validators = ["validator1", "validator2"]
-
-[layout]
-  affinity = "affinity1"
-  agave_affinity = "agave1"
-  net_tile_count = 10
-  quic_tile_count = 5
-  resolv_tile_count = 3
-  verify_tile_count = 7
-  bank_tile_count = 2
-  shred_tile_count = 4
-
-[hugetlbfs]
-  mount_path = "/mnt/hugetlbfs"
-
-[development]
-  [development.gossip]
-    allow_private_address = true
 `
 
 	config := &Config{
@@ -194,3 +199,159 @@ dynamic_port_range = "8000-9000"
 
 	assert.Equal(t, expectedTOML, buf.String(), "Encoded TOML does not match expected")
 }
+
+func TestConfigExtraConfig(t *testing.T) {
+	config := &Config{
+		Ledger: &ConfigLedger{Path: ptr("/home/sol/ledger")},
+		Tiles:  &ConfigTiles{Quic: &ConfigTilesQuic{Retry: ptr(true)}},
+		ExtraConfig: &[]string{
+			"[ledger]\naccounts_index_path = \"/mnt/index\"\n",
+			"[tiles.quic]\nmax_concurrent_connections = 2048\n[tiles.pack]\nmax_pending_transactions = 4096\n",
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, config.Encode(&buf))
+
+	tree := map[string]any{}
+	_, err := toml.Decode(buf.String(), &tree)
+	require.NoError(t, err)
+
+	ledger := tree["ledger"].(map[string]any)
+	assert.Equal(t, "/home/sol/ledger", ledger["path"])
+	assert.Equal(t, "/mnt/index", ledger["accounts_index_path"])
+
+	tiles := tree["tiles"].(map[string]any)
+	assert.Equal(t, map[string]any{"retry": true, "max_concurrent_connections": int64(2048)}, tiles["quic"])
+	assert.Equal(t, map[string]any{"max_pending_transactions": int64(4096)}, tiles["pack"])
+
+	for _, extra := range []string{
+		"[ledger]\npath = \"/mnt/ledger\"\n",
+		"[ledger]\nbogus = 1\n",
+		"[bogus]\nkey = 1\n",
+		"ledger = 1\n",
+		"[ledger.path]\nkey = 1\n",
+		"[ledger\n",
+	} {
+		c := *config
+		c.ExtraConfig = &[]string{extra}
+
+		assert.Error(t, c.Check(), extra)
+		assert.Error(t, c.Encode(&bytes.Buffer{}), extra)
+	}
+}
+
+func TestConfigRelease(t *testing.T) {
+	net := &Config{Net: &ConfigNet{Interface: ptr("eth0"), XDP: &ConfigNetXDP{XDPMode: ptr("drv")}}}
+	legacy := &Config{ExtraConfig: &[]string{"[tiles.net]\ninterface = \"eth0\"\n"}}
+
+	for _, r := range []Release{Release05, Release06} {
+		net.Release = &r
+		legacy.Release = &r
+
+		assert.NoError(t, net.Check())
+		assert.Error(t, legacy.Check())
+	}
+
+	net.Release = ptr(Release04)
+	legacy.Release = ptr(Release04)
+
+	assert.Error(t, net.Check())
+	assert.NoError(t, legacy.Check())
+
+	provider := &Config{Net: &ConfigNet{Provider: ptr("socket")}, Release: ptr(Release05)}
+	assert.Error(t, provider.Check())
+
+	provider.Release = nil
+	assert.NoError(t, provider.Check())
+
+	assert.Error(t, (&Config{Release: ptr(Release("0.1"))}).Check())
+}
+
+func TestReleaseOf(t *testing.T) {
+	for version, expected := range map[string]Release{
+		"0.406.20113-1": Release04,
+		"0.503.20214":   Release05,
+		"v0.607.1":      Release06,
+		"0.905.1-1":     ReleaseLatest,
+	} {
+		r, ok := ReleaseOf(version)
+		assert.True(t, ok, version)
+		assert.Equal(t, expected, r, version)
+	}
+
+	for _, version := range []string{"", "latest", "0.106.1", "1.18.2"} {
+		_, ok := ReleaseOf(version)
+		assert.False(t, ok, version)
+	}
+}
+
+// tomlKeys returns the TOML keys of every field under typ.
+func tomlKeys(prefix string, typ reflect.Type) []string {
+	var keys []string
+
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("toml"), ",")
+
+		if name == "-" {
+			continue
+		}
+
+		ft := typ.Field(i).Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		if ft.Kind() == reflect.Struct {
+			keys = append(keys, tomlKeys(prefix+name+".", ft)...)
+		} else {
+			keys = append(keys, prefix+name)
+		}
+	}
+
+	return keys
+}
+
+func TestConfigSchemaCoversFields(t *testing.T) {
+	known := ReleaseLatest.Keys("")
+
+	for _, k := range tomlKeys("", reflect.TypeOf(Config{})) {
+		assert.True(t, known[k], k)
+	}
+}
+
+func TestConfigSchemaDefaults(t *testing.T) {
+	// testdata/<release>/<variant>.toml hold the default config of
+	// each release.
+	paths, err := filepath.Glob("testdata/*/*.toml")
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+
+	for _, p := range paths {
+		release := Release(filepath.Base(filepath.Dir(p)))
+		variant := Variant(strings.TrimSuffix(filepath.Base(p), ".toml"))
+
+		require.NoError(t, release.Check(), p)
+		require.NoError(t, variant.Check(), p)
+
+		tree := map[string]any{}
+		_, err := toml.DecodeFile(p, &tree)
+		require.NoError(t, err, p)
+
+		assert.NoError(t, release.checkKeys(tree, variant), p)
+	}
+}
+
+func TestConfigSchemaTables(t *testing.T) {
+	ports := &Config{ExtraConfig: &[]string{"[tiles.gossip]\ngossip_listen_port = 8001\n[tiles.repair]\nrepair_intake_listen_port = 8701\n"}}
+	assert.NoError(t, ports.CheckVariant(VariantFrankendancer))
+	assert.NoError(t, ports.CheckVariant(VariantFiredancer))
+
+	// Only the table of Firedancer's own settings is checked.
+	replay := &Config{ExtraConfig: &[]string{"[tiles.replay]\nsnapshot = \"/mnt/snapshot.tar.zst\"\n[funk]\nheap_size_gib = 64\n"}}
+	assert.NoError(t, replay.CheckVariant(VariantFiredancer))
+	assert.Error(t, replay.CheckVariant(VariantFrankendancer))
+
+	replay.ExtraConfig = &[]string{"[tiles.replays]\nsnapshot = \"\"\n"}
+	assert.Error(t, replay.CheckVariant(VariantFiredancer))
+}
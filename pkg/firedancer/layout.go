@@ -0,0 +1,250 @@
+package firedancer
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// minAgaveCores is the fewest cores Frankendancer's Agave is
+	// left with.
+	minAgaveCores = 2
+)
+
+// tileSet is the tiles PlanLayout places for a variant.
+type tileSet struct {
+	// fixed is how many tiles there is always exactly one of, each
+	// needing a core.
+	fixed int
+	// planned are the tiles whose counts are planned, starting at
+	// one each.
+	planned []string
+	// growth is the order spare cores are handed to tiles in.
+	growth []string
+}
+
+var tileSets = map[Variant]tileSet{
+	VariantFrankendancer: {
+		// dedup, pack, poh, store and sign.
+		fixed:   5,
+		planned: []string{"net", "quic", "resolv", "verify", "bank", "shred"},
+		// Verify is the most expensive tile, so it gets two of
+		// every three spare cores, and bank the third.
+		growth: []string{"verify", "verify", "bank"},
+	},
+	VariantFiredancer: {
+		// dedup, pack, poh, store and sign, and gossip, repair and
+		// replay, which Agave does for Frankendancer.
+		fixed:   8,
+		planned: []string{"net", "quic", "resolv", "verify", "bank", "shred", "exec", "writer"},
+		// Replay executes blocks on the exec tiles, so they share
+		// the spare cores with verify, and bank gets what's left.
+		growth: []string{"verify", "exec", "verify", "exec", "bank"},
+	},
+}
+
+// maxTileCounts caps the tiles spare cores are handed to.
+var maxTileCounts = map[string]int{
+	"verify": 16,
+	"bank":   8,
+	"exec":   8,
+}
+
+// PlanLayout plans tile counts and affinities for the host described
+// by t.  Tiles each get a physical core to themselves, leaving the
+// core's SMT siblings idle, and stay off the core CPU 0 belongs to,
+// which the kernel keeps busy.  Beyond one of each, tiles are only
+// added while they fit on a single NUMA node.  Frankendancer's Agave
+// gets the cores the tiles don't, with their siblings, and at least
+// half of those the minimal set of tiles leaves over.  Firedancer has
+// no Agave, and plans exec and writer tiles for replay as well.
+func PlanLayout(t *Topology, variant Variant) (*ConfigLayout, error) {
+	if err := t.Check(); err != nil {
+		return nil, err
+	}
+
+	if err := variant.Check(); err != nil {
+		return nil, err
+	}
+
+	set := tileSets[variant]
+	cores := usableCores(t)
+
+	// One each of the planned tiles, plus the fixed ones.
+	counts := map[string]int{}
+	for _, tile := range set.planned {
+		counts[tile] = 1
+	}
+
+	minimum := len(counts) + set.fixed
+
+	reserved := 0
+	if variant == VariantFrankendancer {
+		reserved = minAgaveCores
+	}
+
+	if len(cores) < minimum+reserved {
+		return nil, fmt.Errorf("%s needs at least %d cores besides core 0's, but the host has %d", variant, minimum+reserved, len(cores))
+	}
+
+	spare := len(cores) - minimum - reserved
+	if variant == VariantFrankendancer {
+		spare /= 2
+	}
+
+	perNode, _ := coresPerNode(cores)
+
+	if largest := slices.Max(slices.Collect(maps.Values(perNode))); largest >= minimum {
+		spare = min(spare, largest-minimum)
+	}
+
+	for added := true; spare > 0 && added; {
+		added = false
+
+		for _, tile := range set.growth {
+			if spare == 0 {
+				break
+			}
+
+			if counts[tile] >= maxTileCounts[tile] {
+				continue
+			}
+
+			counts[tile]++
+			spare--
+			added = true
+		}
+	}
+
+	tiles := set.fixed
+	for _, n := range counts {
+		tiles += n
+	}
+
+	cores = preferNode(cores, tiles)
+
+	var tileCPUs []int
+	for _, c := range cores[:tiles] {
+		tileCPUs = append(tileCPUs, c.cpus[0])
+	}
+
+	layout := &ConfigLayout{
+		Affinity:        ptrTo(formatCPUList(tileCPUs)),
+		NetTileCount:    ptrTo(counts["net"]),
+		QuicTileCount:   ptrTo(counts["quic"]),
+		ResolvTileCount: ptrTo(counts["resolv"]),
+		VerifyTileCount: ptrTo(counts["verify"]),
+		BankTileCount:   ptrTo(counts["bank"]),
+		ShredTileCount:  ptrTo(counts["shred"]),
+	}
+
+	switch variant {
+	case VariantFrankendancer:
+		var agaveCPUs []int
+		for _, c := range cores[tiles:] {
+			agaveCPUs = append(agaveCPUs, c.cpus...)
+		}
+
+		slices.Sort(agaveCPUs)
+		layout.AgaveAffinity = ptrTo(formatCPUList(agaveCPUs))
+	case VariantFiredancer:
+		layout.ExecTileCount = ptrTo(counts["exec"])
+		layout.WriterTileCount = ptrTo(counts["writer"])
+	}
+
+	return layout, nil
+}
+
+func ptrTo[T any](v T) *T {
+	return &v
+}
+
+// usableCores returns the physical cores tiles may be placed on: all
+// but the one CPU 0 belongs to.
+func usableCores(t *Topology) []physicalCore {
+	var cores []physicalCore
+
+	for _, c := range t.cores() {
+		if !slices.Contains(c.cpus, 0) {
+			cores = append(cores, c)
+		}
+	}
+
+	return cores
+}
+
+// preferNode reorders cores so that the first n are on a single NUMA
+// node, the lowest numbered one with enough cores.  If no node has
+// enough, the nodes with the most cores come first.
+func preferNode(cores []physicalCore, n int) []physicalCore {
+	perNode, nodes := coresPerNode(cores)
+
+	rank := map[int]int{}
+
+	fit := slices.IndexFunc(nodes, func(node int) bool { return perNode[node] >= n })
+
+	if fit >= 0 {
+		for _, node := range nodes {
+			rank[node] = 1
+		}
+
+		rank[nodes[fit]] = 0
+	} else {
+		byCores := slices.Clone(nodes)
+		slices.SortStableFunc(byCores, func(a, b int) int { return perNode[b] - perNode[a] })
+
+		for i, node := range byCores {
+			rank[node] = i
+		}
+	}
+
+	ordered := slices.Clone(cores)
+	slices.SortStableFunc(ordered, func(a, b physicalCore) int { return rank[a.node] - rank[b.node] })
+
+	return ordered
+}
+
+// coresPerNode counts the cores on each NUMA node, and returns the
+// nodes in order.
+func coresPerNode(cores []physicalCore) (map[int]int, []int) {
+	perNode := map[int]int{}
+	var nodes []int
+
+	for _, c := range cores {
+		if perNode[c.node] == 0 {
+			nodes = append(nodes, c.node)
+		}
+
+		perNode[c.node]++
+	}
+
+	slices.Sort(nodes)
+
+	return perNode, nodes
+}
+
+// formatCPUList formats CPUs in the order given, collapsing ascending
+// runs into ranges, e.g. "1-4,8".
+func formatCPUList(cpus []int) string {
+	var parts []string
+
+	for i := 0; i < len(cpus); {
+		j := i
+		for j+1 < len(cpus) && cpus[j+1] == cpus[j]+1 {
+			j++
+		}
+
+		if j == i {
+			parts = append(parts, strconv.Itoa(cpus[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", cpus[i], cpus[j]))
+		}
+
+		i = j + 1
+	}
+
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,148 @@
+package firedancer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanLayout(t *testing.T) {
+	for _, tc := range []struct {
+		name                    string
+		sockets, cores, threads int
+		variant                 Variant
+		expected                ConfigLayout
+	}{
+		{
+			name:    "single socket, 16 cores with SMT",
+			sockets: 1, cores: 16, threads: 2,
+			variant: VariantFrankendancer,
+			expected: ConfigLayout{
+				Affinity:        ptr("1-12"),
+				AgaveAffinity:   ptr("13-15,29-31"),
+				NetTileCount:    ptr(1),
+				QuicTileCount:   ptr(1),
+				ResolvTileCount: ptr(1),
+				VerifyTileCount: ptr(2),
+				BankTileCount:   ptr(1),
+				ShredTileCount:  ptr(1),
+			},
+		},
+		{
+			name:    "single socket, 24 cores with SMT, Firedancer",
+			sockets: 1, cores: 24, threads: 2,
+			variant: VariantFiredancer,
+			expected: ConfigLayout{
+				Affinity:        ptr("1-23"),
+				ExecTileCount:   ptr(4),
+				WriterTileCount: ptr(1),
+				NetTileCount:    ptr(1),
+				QuicTileCount:   ptr(1),
+				ResolvTileCount: ptr(1),
+				VerifyTileCount: ptr(4),
+				BankTileCount:   ptr(2),
+				ShredTileCount:  ptr(1),
+			},
+		},
+		{
+			name:    "two NUMA nodes of 12 cores with SMT",
+			sockets: 2, cores: 12, threads: 2,
+			variant: VariantFrankendancer,
+			expected: ConfigLayout{
+				// Node 0 loses core 0, so only node 1 fits the tiles.
+				Affinity:        ptr("12-23"),
+				AgaveAffinity:   ptr("1-11,25-35"),
+				NetTileCount:    ptr(1),
+				QuicTileCount:   ptr(1),
+				ResolvTileCount: ptr(1),
+				VerifyTileCount: ptr(2),
+				BankTileCount:   ptr(1),
+				ShredTileCount:  ptr(1),
+			},
+		},
+		{
+			name:    "two NUMA nodes of 32 cores without SMT",
+			sockets: 2, cores: 32, threads: 1,
+			variant: VariantFrankendancer,
+			expected: ConfigLayout{
+				// The tiles grow to fill node 1, and no further.
+				Affinity:        ptr("32-63"),
+				AgaveAffinity:   ptr("1-31"),
+				NetTileCount:    ptr(1),
+				QuicTileCount:   ptr(1),
+				ResolvTileCount: ptr(1),
+				VerifyTileCount: ptr(15),
+				BankTileCount:   ptr(8),
+				ShredTileCount:  ptr(1),
+			},
+		},
+		{
+			name:    "two NUMA nodes too small for the tiles",
+			sockets: 2, cores: 10, threads: 2,
+			variant: VariantFiredancer,
+			expected: ConfigLayout{
+				Affinity:        ptr("10-19,1-9"),
+				ExecTileCount:   ptr(2),
+				WriterTileCount: ptr(1),
+				NetTileCount:    ptr(1),
+				QuicTileCount:   ptr(1),
+				ResolvTileCount: ptr(1),
+				VerifyTileCount: ptr(3),
+				BankTileCount:   ptr(1),
+				ShredTileCount:  ptr(1),
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			topo, err := ParseLSCPU(lscpuOutput(tc.sockets, tc.cores, tc.threads))
+			require.NoError(t, err)
+
+			layout, err := PlanLayout(topo, tc.variant)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, *layout)
+
+			config := Config{Layout: layout}
+			assert.NoError(t, config.CheckVariant(tc.variant))
+		})
+	}
+}
+
+func TestPlanLayoutTooSmall(t *testing.T) {
+	// 8 vCPUs: 4 cores with SMT.
+	topo, err := ParseLSCPU(lscpuOutput(1, 4, 2))
+	require.NoError(t, err)
+
+	_, err = PlanLayout(topo, VariantFrankendancer)
+	assert.Error(t, err)
+
+	// 12 cores are too few for Frankendancer, which must leave
+	// some for Agave.
+	topo, err = ParseLSCPU(lscpuOutput(1, 12, 1))
+	require.NoError(t, err)
+
+	_, err = PlanLayout(topo, VariantFrankendancer)
+	assert.Error(t, err)
+
+	// 14 are enough for Frankendancer, but not for Firedancer, whose
+	// tiles do what Agave does for Frankendancer as well.
+	topo, err = ParseLSCPU(lscpuOutput(1, 14, 1))
+	require.NoError(t, err)
+
+	_, err = PlanLayout(topo, VariantFrankendancer)
+	assert.NoError(t, err)
+
+	_, err = PlanLayout(topo, VariantFiredancer)
+	assert.Error(t, err)
+
+	_, err = PlanLayout(&Topology{}, VariantFiredancer)
+	assert.Error(t, err)
+}
+
+func TestFormatCPUList(t *testing.T) {
+	assert.Equal(t, "", formatCPUList(nil))
+	assert.Equal(t, "3", formatCPUList([]int{3}))
+	assert.Equal(t, "1-4,8,10-11", formatCPUList([]int{1, 2, 3, 4, 8, 10, 11}))
+	assert.Equal(t, "6-7,1-2", formatCPUList([]int{6, 7, 1, 2}))
+}
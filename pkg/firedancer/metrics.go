@@ -0,0 +1,203 @@
+package firedancer
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/abklabs/svmkit/pkg/monitoring"
+)
+
+const (
+	defaultMetricAddress = "0.0.0.0"
+	defaultMetricPort    = 7999
+	defaultGuiAddress    = "127.0.0.1"
+	defaultGuiPort       = 80
+)
+
+// Endpoint is an address and TCP port a tile listens on.
+type Endpoint struct {
+	Address string
+	Port    int
+}
+
+func (e Endpoint) String() string {
+	return net.JoinHostPort(e.Address, strconv.Itoa(e.Port))
+}
+
+// Local reports whether only the host itself can reach the endpoint.
+func (e Endpoint) Local() bool {
+	ip := net.ParseIP(e.Address)
+
+	return ip != nil && ip.IsLoopback()
+}
+
+func (e Endpoint) Check() error {
+	if net.ParseIP(e.Address) == nil {
+		return fmt.Errorf("invalid listen address '%s'", e.Address)
+	}
+
+	if e.Port < 1 || e.Port > 65535 {
+		return fmt.Errorf("invalid listen port %d", e.Port)
+	}
+
+	return nil
+}
+
+// MetricEndpoint returns where the metric tile serves Prometheus
+// metrics, whether set by the typed fields or ExtraConfig.
+func (c *Config) MetricEndpoint() (*Endpoint, error) {
+	tree, err := c.merged("")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return metricEndpoint(tree)
+}
+
+// GuiEndpoint returns where the gui tile serves the dashboard, or nil
+// if it is disabled.
+func (c *Config) GuiEndpoint() (*Endpoint, error) {
+	tree, err := c.merged("")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return guiEndpoint(tree)
+}
+
+func metricEndpoint(tree map[string]any) (*Endpoint, error) {
+	return tileEndpoint(tree, "metric", "prometheus_listen_address", "prometheus_listen_port", Endpoint{defaultMetricAddress, defaultMetricPort})
+}
+
+func guiEndpoint(tree map[string]any) (*Endpoint, error) {
+	enabled := true
+
+	if v, ok := tileValue(tree, "gui", "enabled"); ok {
+		b, ok := v.(bool)
+
+		if !ok {
+			return nil, fmt.Errorf("tiles.gui.enabled must be a boolean")
+		}
+
+		enabled = b
+	}
+
+	if !enabled {
+		return nil, nil
+	}
+
+	return tileEndpoint(tree, "gui", "gui_listen_address", "gui_listen_port", Endpoint{defaultGuiAddress, defaultGuiPort})
+}
+
+// tileEndpoint reads a tile's listen address and port from the merged
+// config tree, falling back to def for those unset.
+func tileEndpoint(tree map[string]any, tile, addressKey, portKey string, def Endpoint) (*Endpoint, error) {
+	e := def
+
+	if v, ok := tileValue(tree, tile, addressKey); ok {
+		s, ok := v.(string)
+
+		if !ok {
+			return nil, fmt.Errorf("tiles.%s.%s must be a string", tile, addressKey)
+		}
+
+		e.Address = s
+	}
+
+	if v, ok := tileValue(tree, tile, portKey); ok {
+		n, ok := v.(int64)
+
+		if !ok {
+			return nil, fmt.Errorf("tiles.%s.%s must be an integer", tile, portKey)
+		}
+
+		e.Port = int(n)
+	}
+
+	if err := e.Check(); err != nil {
+		return nil, fmt.Errorf("firedancer %s tile: %w", tile, err)
+	}
+
+	return &e, nil
+}
+
+func tileValue(tree map[string]any, tile, key string) (any, bool) {
+	tiles, ok := tree["tiles"].(map[string]any)
+
+	if !ok {
+		return nil, false
+	}
+
+	t, ok := tiles[tile].(map[string]any)
+
+	if !ok {
+		return nil, false
+	}
+
+	v, ok := t[key]
+
+	return v, ok
+}
+
+// FirewallRules returns the ufw rules, for firewall.FirewallParams,
+// that open the metric and gui tiles to the sources given, or to
+// anyone if none are.  Endpoints only the host can reach need none.
+func (fd *Firedancer) FirewallRules(from ...string) ([]string, error) {
+	metric, err := fd.Config.MetricEndpoint()
+
+	if err != nil {
+		return nil, err
+	}
+
+	gui, err := fd.Config.GuiEndpoint()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []string
+
+	for _, e := range []*Endpoint{metric, gui} {
+		if e == nil || e.Local() {
+			continue
+		}
+
+		if len(from) == 0 {
+			rules = append(rules, fmt.Sprintf("%d/tcp", e.Port))
+			continue
+		}
+
+		for _, src := range from {
+			rules = append(rules, fmt.Sprintf("from %s to any port %d proto tcp", src, e.Port))
+		}
+	}
+
+	return rules, nil
+}
+
+// MetricsTarget returns the scrape target for the validator's metrics,
+// as reached at host.  Without a host, the metric tile's own address
+// is used, or the loopback address if it listens on all of them.
+func (fd *Firedancer) MetricsTarget(host string) (*monitoring.Target, error) {
+	metric, err := fd.Config.MetricEndpoint()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if host == "" {
+		host = metric.Address
+
+		if net.ParseIP(host).IsUnspecified() {
+			host = "127.0.0.1"
+		}
+	}
+
+	return &monitoring.Target{
+		Job:     fd.GetVariant().ServiceName(),
+		Targets: []string{net.JoinHostPort(host, strconv.Itoa(metric.Port))},
+	}, nil
+}
@@ -0,0 +1,95 @@
+package firedancer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigEndpoints(t *testing.T) {
+	config := Config{}
+
+	metric, err := config.MetricEndpoint()
+	require.NoError(t, err)
+	assert.Equal(t, &Endpoint{"0.0.0.0", 7999}, metric)
+
+	gui, err := config.GuiEndpoint()
+	require.NoError(t, err)
+	assert.Equal(t, &Endpoint{"127.0.0.1", 80}, gui)
+	assert.True(t, gui.Local())
+
+	config = Config{
+		Tiles: &ConfigTiles{
+			Metric: &ConfigTilesMetric{PrometheusListenAddress: ptr("10.0.0.5")},
+			Gui:    &ConfigTilesGui{Enabled: ptr(false)},
+		},
+		ExtraConfig: &[]string{"[tiles.metric]\nprometheus_listen_port = 9100\n"},
+	}
+
+	metric, err = config.MetricEndpoint()
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.5:9100", metric.String())
+
+	gui, err = config.GuiEndpoint()
+	require.NoError(t, err)
+	assert.Nil(t, gui)
+
+	for _, config := range []Config{
+		{Tiles: &ConfigTiles{Metric: &ConfigTilesMetric{PrometheusListenPort: ptr(0)}}},
+		{Tiles: &ConfigTiles{Metric: &ConfigTilesMetric{PrometheusListenAddress: ptr("localhost")}}},
+		{Tiles: &ConfigTiles{Gui: &ConfigTilesGui{GuiListenPort: ptr(70000)}}},
+		{ExtraConfig: &[]string{"[tiles.metric]\nprometheus_listen_port = \"7999\"\n"}},
+		{ExtraConfig: &[]string{"[tiles.gui]\nenabled = \"yes\"\n"}},
+	} {
+		assert.Error(t, config.Check())
+	}
+
+	// A disabled gui tile's endpoint isn't checked.
+	config = Config{Tiles: &ConfigTiles{Gui: &ConfigTilesGui{Enabled: ptr(false), GuiListenPort: ptr(0)}}}
+	assert.NoError(t, config.Check())
+}
+
+func TestFirewallRules(t *testing.T) {
+	fd := Firedancer{}
+
+	rules, err := fd.FirewallRules()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"7999/tcp"}, rules)
+
+	fd.Config.Tiles = &ConfigTiles{
+		Metric: &ConfigTilesMetric{PrometheusListenPort: ptr(9100)},
+		Gui:    &ConfigTilesGui{GuiListenAddress: ptr("0.0.0.0"), GuiListenPort: ptr(8080)},
+	}
+
+	rules, err = fd.FirewallRules("10.0.0.0/8")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"from 10.0.0.0/8 to any port 9100 proto tcp",
+		"from 10.0.0.0/8 to any port 8080 proto tcp",
+	}, rules)
+
+	fd.Config.Tiles = &ConfigTiles{Metric: &ConfigTilesMetric{PrometheusListenAddress: ptr("127.0.0.1")}}
+
+	rules, err = fd.FirewallRules()
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+}
+
+func TestMetricsTarget(t *testing.T) {
+	fd := Firedancer{}
+
+	target, err := fd.MetricsTarget("")
+	require.NoError(t, err)
+	assert.Equal(t, "svmkit-fd-validator", target.Job)
+	assert.Equal(t, []string{"127.0.0.1:7999"}, target.Targets)
+	assert.NoError(t, target.Check())
+
+	fd.Variant = ptr(VariantFiredancer)
+	fd.Config.Tiles = &ConfigTiles{Metric: &ConfigTilesMetric{PrometheusListenPort: ptr(9100)}}
+
+	target, err = fd.MetricsTarget("validator.internal")
+	require.NoError(t, err)
+	assert.Equal(t, "svmkit-firedancer-validator", target.Job)
+	assert.Equal(t, []string{"validator.internal:9100"}, target.Targets)
+}
@@ -0,0 +1,401 @@
+package firedancer
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pulumi/pulumi-go-provider/infer"
+)
+
+// Release is a line of Firedancer releases sharing a config schema,
+// e.g. "0.5" for the 0.5xx releases.
+type Release string
+
+const (
+	Release04 Release = "0.4"
+	Release05 Release = "0.5"
+	Release06 Release = "0.6"
+
+	ReleaseLatest = Release06
+)
+
+// releases lists the known releases, oldest first.
+var releases = []Release{Release04, Release05, Release06}
+
+var versionRegexp = regexp.MustCompile(`^v?0\.([0-9])[0-9]{2}\.`)
+
+func (Release) Values() []infer.EnumValue[Release] {
+	return []infer.EnumValue[Release]{
+		{
+			Name:        "v04",
+			Value:       Release04,
+			Description: "The 0.4xx releases",
+		},
+		{
+			Name:        "v05",
+			Value:       Release05,
+			Description: "The 0.5xx releases",
+		},
+		{
+			Name:        "v06",
+			Value:       Release06,
+			Description: "The 0.6xx releases",
+		},
+	}
+}
+
+func (r Release) Check() error {
+	if !slices.Contains(releases, r) {
+		return fmt.Errorf("unknown firedancer release '%s'", r)
+	}
+
+	return nil
+}
+
+// ReleaseOf returns the release a package version, e.g.
+// "0.503.20214-1", belongs to.  Versions newer than any known release
+// map to the latest one.
+func ReleaseOf(version string) (Release, bool) {
+	m := versionRegexp.FindStringSubmatch(version)
+
+	if m == nil {
+		return "", false
+	}
+
+	r := Release("0." + m[1])
+
+	if slices.Contains(releases, r) {
+		return r, true
+	}
+
+	if r > ReleaseLatest {
+		return ReleaseLatest, true
+	}
+
+	return "", false
+}
+
+// configSchema lists the keys fdctl accepts, by the releases and
+// variant that accept them.  An empty since or until leaves that end
+// open, and an empty variant means both.  A key ending in ".*" names a
+// table whose keys change from build to build, so only the table is
+// checked.  Each release's default config, in testdata, must pass.
+var configSchema = []struct {
+	since, until Release
+	variant      Variant
+	keys         []string
+}{
+	{
+		keys: []string{
+			"name",
+			"user",
+			"scratch_directory",
+			"dynamic_port_range",
+
+			"log.path",
+			"log.colorize",
+			"log.level_logfile",
+			"log.level_stderr",
+			"log.level_flush",
+
+			"reporting.solana_metrics_config",
+
+			"ledger.path",
+			"ledger.accounts_path",
+			"ledger.limit_size",
+			"ledger.account_indexes",
+			"ledger.account_index_exclude_keys",
+			"ledger.account_index_include_keys",
+			"ledger.accounts_index_path",
+			"ledger.accounts_hash_cache_path",
+			"ledger.snapshot_archive_format",
+			"ledger.require_tower",
+
+			"gossip.entrypoints",
+			"gossip.port_check",
+			"gossip.port",
+			"gossip.host",
+
+			"rpc.port",
+			"rpc.full_api",
+			"rpc.private",
+			"rpc.transaction_history",
+			"rpc.extended_tx_metadata_storage",
+			"rpc.only_known",
+			"rpc.pubsub_enable_block_subscription",
+			"rpc.pubsub_enable_vote_subscription",
+			"rpc.bigtable_ledger_storage",
+
+			"snapshots.incremental_snapshots",
+			"snapshots.full_snapshot_interval_slots",
+			"snapshots.incremental_snapshot_interval_slots",
+			"snapshots.minimum_snapshot_download_speed",
+			"snapshots.maximum_full_snapshots_to_retain",
+			"snapshots.maximum_incremental_snapshots_to_retain",
+			"snapshots.path",
+			"snapshots.incremental_path",
+
+			"consensus.identity_path",
+			"consensus.vote_account_path",
+			"consensus.authorized_voter_paths",
+			"consensus.snapshot_fetch",
+			"consensus.genesis_fetch",
+			"consensus.poh_speed_test",
+			"consensus.expected_genesis_hash",
+			"consensus.wait_for_supermajority_at_slot",
+			"consensus.expected_bank_hash",
+			"consensus.expected_shred_version",
+			"consensus.wait_for_vote_to_start_leader",
+			"consensus.os_network_limits_test",
+			"consensus.hard_fork_at_slots",
+			"consensus.known_validators",
+
+			"layout.affinity",
+			"layout.net_tile_count",
+			"layout.quic_tile_count",
+			"layout.resolv_tile_count",
+			"layout.verify_tile_count",
+			"layout.bank_tile_count",
+			"layout.shred_tile_count",
+
+			"hugetlbfs.mount_path",
+
+			"tiles.quic.regular_transaction_listen_port",
+			"tiles.quic.quic_transaction_listen_port",
+			"tiles.quic.txn_reassembly_count",
+			"tiles.quic.max_concurrent_connections",
+			"tiles.quic.max_concurrent_handshakes",
+			"tiles.quic.idle_timeout_millis",
+			"tiles.quic.ack_delay_millis",
+			"tiles.quic.retry",
+			"tiles.verify.signature_cache_size",
+			"tiles.verify.receive_buffer_size",
+			"tiles.verify.mtu",
+			"tiles.dedup.signature_cache_size",
+			"tiles.pack.max_pending_transactions",
+			"tiles.pack.use_consumed_cus",
+			"tiles.bank.*",
+			"tiles.poh.lagged_consecutive_leader_start",
+			"tiles.shred.max_pending_shred_sets",
+			"tiles.shred.shred_listen_port",
+			"tiles.sign.*",
+			"tiles.gossip.gossip_listen_port",
+			"tiles.repair.repair_intake_listen_port",
+			"tiles.repair.repair_serve_listen_port",
+			"tiles.metric.prometheus_listen_address",
+			"tiles.metric.prometheus_listen_port",
+			"tiles.gui.enabled",
+			"tiles.gui.gui_listen_address",
+			"tiles.gui.gui_listen_port",
+			"tiles.gui.max_http_connections",
+			"tiles.gui.max_websocket_connections",
+			"tiles.gui.max_http_request_length",
+			"tiles.gui.send_buffer_size_mb",
+
+			"development.sandbox",
+			"development.no_clone",
+			"development.core_dump",
+			"development.bootstrap",
+			"development.netns.enabled",
+			"development.netns.interface0",
+			"development.netns.interface0_mac",
+			"development.netns.interface0_addr",
+			"development.netns.interface1",
+			"development.netns.interface1_mac",
+			"development.netns.interface1_addr",
+			"development.gossip.allow_private_address",
+			"development.genesis.hashes_per_tick",
+			"development.genesis.target_tick_duration_micros",
+			"development.genesis.ticks_per_slot",
+			"development.genesis.fund_initial_accounts",
+			"development.genesis.fund_initial_amount_lamports",
+			"development.genesis.vote_account_stake_lamports",
+			"development.genesis.warmup_epochs",
+			"development.bench.benchg_tile_count",
+			"development.bench.benchs_tile_count",
+			"development.bench.affinity",
+			"development.bench.larger_max_cost_per_block",
+			"development.bench.larger_shred_limits_per_block",
+			"development.bench.disable_blockstore_from_slot",
+			"development.bench.disable_status_cache",
+			"development.pktgen.affinity",
+		},
+	},
+	{
+		// Only Frankendancer runs Agave alongside its tiles.
+		variant: VariantFrankendancer,
+		keys: []string{
+			"layout.agave_affinity",
+			"development.no_agave",
+		},
+	},
+	{
+		since:   Release05,
+		variant: VariantFrankendancer,
+		keys: []string{
+			"layout.agave_unified_scheduler_handler_threads",
+		},
+	},
+	{
+		// Firedancer executes transactions in its own tiles.
+		since:   Release05,
+		variant: VariantFiredancer,
+		keys: []string{
+			"layout.exec_tile_count",
+			"layout.writer_tile_count",
+
+			"blockstore.*",
+			"funk.*",
+			"runtime.*",
+			"tiles.replay.*",
+		},
+	},
+	{
+		// The net tile's settings moved to [net] in 0.5.
+		until: Release05,
+		keys: []string{
+			"tiles.net.interface",
+			"tiles.net.xdp_mode",
+			"tiles.net.xdp_rx_queue_size",
+			"tiles.net.xdp_tx_queue_size",
+			"tiles.net.xdp_aio_depth",
+			"tiles.net.send_buffer_size",
+		},
+	},
+	{
+		since: Release05,
+		keys: []string{
+			"ledger.enable_accounts_disk_index",
+			"rpc.bind_address",
+			"snapshots.enabled",
+
+			"net.interface",
+			"net.bind_address",
+			"net.xdp.xdp_mode",
+			"net.xdp.xdp_zero_copy",
+			"net.xdp.xdp_rx_queue_size",
+			"net.xdp.xdp_tx_queue_size",
+			"net.xdp.flush_timeout_micros",
+
+			"tiles.netlink.max_routes",
+			"tiles.netlink.max_neighbors",
+			"tiles.bundle.enabled",
+			"tiles.bundle.url",
+			"tiles.bundle.tls_domain_name",
+			"tiles.bundle.tip_distribution_program_addr",
+			"tiles.bundle.tip_payment_program_addr",
+			"tiles.bundle.tip_distribution_authority",
+			"tiles.bundle.commission_bps",
+			"tiles.bundle.keepalive_interval_millis",
+
+			"development.bundle.ssl_key_log_file",
+			"development.bundle.buffer_size_kib",
+			"development.pktgen.fake_dst_ip",
+		},
+	},
+	{
+		since: Release06,
+		keys: []string{
+			"rpc.public_address",
+
+			"net.provider",
+			"net.socket.receive_buffer_size",
+			"net.socket.send_buffer_size",
+
+			"tiles.bundle.tls_cert_verify",
+			"tiles.pack.schedule_strategy",
+			"tiles.shred.additional_shred_destinations_retransmit",
+			"tiles.shred.additional_shred_destinations_leader",
+		},
+	},
+}
+
+// Keys returns the config keys, e.g. "tiles.quic.retry", the release
+// accepts for variant, or for either variant if it is empty.  Tables
+// whose keys aren't checked appear as e.g. "tiles.replay.*".
+func (r Release) Keys(variant Variant) map[string]bool {
+	i := slices.Index(releases, r)
+	keys := map[string]bool{}
+
+	for _, s := range configSchema {
+		if variant != "" && s.variant != "" && s.variant != variant {
+			continue
+		}
+
+		if s.since != "" && i < slices.Index(releases, s.since) {
+			continue
+		}
+
+		if s.until != "" && i >= slices.Index(releases, s.until) {
+			continue
+		}
+
+		for _, k := range s.keys {
+			keys[k] = true
+		}
+	}
+
+	return keys
+}
+
+// checkKeys returns an error for the first key in config, in key
+// order, that the release doesn't accept for variant.
+func (r Release) checkKeys(config map[string]any, variant Variant) error {
+	keys := r.Keys(variant)
+
+	what := string(r)
+	if variant != "" {
+		what = fmt.Sprintf("%s %s", variant, r)
+	}
+
+	var walk func(prefix string, m map[string]any) error
+
+	walk = func(prefix string, m map[string]any) error {
+		if keys[prefix+"*"] {
+			return nil
+		}
+
+		names := make([]string, 0, len(m))
+		for k := range m {
+			names = append(names, k)
+		}
+
+		slices.Sort(names)
+
+		for _, k := range names {
+			path := prefix + k
+
+			if sub, ok := m[k].(map[string]any); ok {
+				if !isTable(keys, path) {
+					return fmt.Errorf("unknown firedancer %s config table '%s'", what, path)
+				}
+
+				if err := walk(path+".", sub); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			if !keys[path] {
+				return fmt.Errorf("unknown firedancer %s config key '%s'", what, path)
+			}
+		}
+
+		return nil
+	}
+
+	return walk("", config)
+}
+
+func isTable(keys map[string]bool, path string) bool {
+	for k := range keys {
+		if strings.HasPrefix(k, path+".") {
+			return true
+		}
+	}
+
+	return false
+}
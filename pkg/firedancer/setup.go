@@ -4,16 +4,14 @@ import (
 	"fmt"
 
 	"github.com/abklabs/svmkit/pkg/deletion"
+	"github.com/abklabs/svmkit/pkg/logging"
+	"github.com/abklabs/svmkit/pkg/machine/user"
 	"github.com/abklabs/svmkit/pkg/runner"
 	"github.com/abklabs/svmkit/pkg/runner/deb"
 	"github.com/abklabs/svmkit/pkg/solana"
 	"github.com/abklabs/svmkit/pkg/validator"
 )
 
-const (
-	identityKeyPairPath = "/home/sol/validator-keypair.json"
-)
-
 type KeyPairs struct {
 	Identity    string `pulumi:"identity" provider:"secret"`
 	VoteAccount string `pulumi:"voteAccount" provider:"secret"`
@@ -26,6 +24,8 @@ type Firedancer struct {
 	Version        *string             `pulumi:"version,optional"`
 	Variant        *Variant            `pulumi:"variant,optional"`
 	DeletionPolicy *deletion.Policy    `pulumi:"deletionPolicy,optional"`
+	ServiceUser    *user.ServiceUser   `pulumi:"serviceUser,optional"`
+	Logging        *logging.Config     `pulumi:"logging,optional"`
 
 	KeyPairs KeyPairs `pulumi:"keyPairs"`
 	Config   Config   `pulumi:"config"`
@@ -37,12 +37,34 @@ func (fd *Firedancer) Install() runner.Command {
 	}
 }
 
+func (fd *Firedancer) Update() runner.Command {
+	return &UpdateCommand{
+		InstallCommand: InstallCommand{
+			Firedancer: *fd,
+		},
+	}
+}
+
 func (fd *Firedancer) Uninstall() runner.Command {
 	return &UninstallCommand{
 		Firedancer: *fd,
 	}
 }
 
+// ConfigPath is where the generated config.toml is installed.
+func (fd *Firedancer) ConfigPath() string {
+	return fd.ServiceUser.Path("config.toml")
+}
+
+// LogFiles returns the log files the validator is configured to write.
+func (fd *Firedancer) LogFiles() []string {
+	if l := fd.Config.Log; l != nil && l.Path != nil {
+		return []string{*l.Path}
+	}
+
+	return nil
+}
+
 func (fd *Firedancer) GetVariant() Variant {
 	if fd.Variant == nil {
 		return VariantFrankendancer
@@ -87,8 +109,16 @@ type InstallCommand struct {
 func (c *InstallCommand) Check() error {
 	c.SetConfigDefaults()
 
-	pkgGrp := deb.Package{}.MakePackageGroup("svmkit-solana-cli")
-	pkgGrp.Add(deb.Package{Name: "svmkit-frankendancer", Version: c.Version})
+	if err := c.ServiceUser.Check(); err != nil {
+		return err
+	}
+
+	// fdctl drops privileges to this user, so keep it in step with
+	// the account owning the validator's files.
+	if c.ServiceUser != nil && c.Firedancer.Config.User == nil {
+		name := c.ServiceUser.GetName()
+		c.Firedancer.Config.User = &name
+	}
 
 	variant := c.GetVariant()
 
@@ -98,6 +128,19 @@ func (c *InstallCommand) Check() error {
 
 	c.Variant = &variant
 
+	if c.Firedancer.Config.Release == nil && c.Version != nil {
+		if release, ok := ReleaseOf(*c.Version); ok {
+			c.Firedancer.Config.Release = &release
+		}
+	}
+
+	if err := c.Firedancer.Config.CheckVariant(variant); err != nil {
+		return err
+	}
+
+	pkgGrp := deb.Package{}.MakePackageGroup("svmkit-solana-cli")
+	pkgGrp.Add(deb.Package{Name: variant.PackageName(), Version: c.Version})
+
 	if err := c.UpdatePackageGroup(pkgGrp); err != nil {
 		return err
 	}
@@ -115,6 +158,10 @@ func (c *InstallCommand) Check() error {
 
 	c.DeletionPolicy = &policy
 
+	if err := c.Logging.CheckFor(logging.ProfileFiredancer); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -122,7 +169,7 @@ func (c *InstallCommand) Env() *runner.EnvBuilder {
 	e := runner.NewEnvBuilder()
 
 	{
-		s := identityKeyPairPath
+		s := c.ServiceUser.Path("validator-keypair.json")
 		conf := solana.CLIConfig{
 			KeyPair: &s,
 		}
@@ -135,8 +182,11 @@ func (c *InstallCommand) Env() *runner.EnvBuilder {
 	}
 
 	e.Merge(c.RunnerCommand.Env())
+	e.Merge(c.ServiceUser.Env())
 	e.Set("VALIDATOR_PACKAGE", c.Variant.PackageName())
 	e.Set("VALIDATOR_SERVICE", c.Variant.ServiceName())
+	e.Set("SETUP_SERVICE", c.Variant.SetupServiceName())
+	e.SetArray("OTHER_VARIANT_SERVICES", c.Variant.OtherServiceNames())
 
 	c.DeletionPolicy.Create(&c.Firedancer, e)
 
@@ -144,14 +194,6 @@ func (c *InstallCommand) Env() *runner.EnvBuilder {
 }
 
 func (c *InstallCommand) AddToPayload(p *runner.Payload) error {
-	{
-		w := p.NewWriter(runner.PayloadFile{Path: "config.toml"})
-
-		if err := c.Firedancer.Config.Encode(w); err != nil {
-			return err
-		}
-	}
-
 	{
 		r, err := assets.Open(assetsInstall)
 
@@ -162,24 +204,26 @@ func (c *InstallCommand) AddToPayload(p *runner.Payload) error {
 		p.AddReader(runner.ScriptNameSteps, r)
 	}
 
+	return c.addValidatorFiles(p)
+}
+
+// addValidatorFiles adds everything but the steps script, which is
+// shared between installing and updating the validator.
+func (c *InstallCommand) addValidatorFiles(p *runner.Payload) error {
 	{
-		r, err := assets.Open(assetsFDService)
+		w := p.NewWriter(runner.PayloadFile{Path: "config.toml"})
 
-		if err != nil {
+		if err := c.Firedancer.Config.Encode(w); err != nil {
 			return err
 		}
-
-		p.AddReader(fmt.Sprintf("%s.service", c.Variant.ServiceName()), r)
 	}
 
-	{
-		r, err := assets.Open(assetsFDSetupService)
-
-		if err != nil {
-			return err
-		}
+	if err := p.AddTemplate(fmt.Sprintf("%s.service", c.Variant.ServiceName()), fdServiceTmpl, c); err != nil {
+		return err
+	}
 
-		p.AddReader("svmkit-fd-setup.service", r)
+	if err := p.AddTemplate(fmt.Sprintf("%s.service", c.Variant.SetupServiceName()), fdSetupServiceTmpl, c); err != nil {
+		return err
 	}
 
 	p.AddString("validator-keypair.json", c.KeyPairs.Identity)
@@ -193,6 +237,16 @@ func (c *InstallCommand) AddToPayload(p *runner.Payload) error {
 		return err
 	}
 
+	logConfig, err := c.Logging.Resolve(logging.ProfileFiredancer)
+
+	if err != nil {
+		return err
+	}
+
+	if err := logConfig.AddToPayload(p, c.Variant.ServiceName(), c.LogFiles()...); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -200,6 +254,27 @@ func (c *InstallCommand) Config() *runner.Config {
 	return c.RunnerConfig
 }
 
+// UpdateCommand updates an installed validator in place.  Rather than
+// running the whole install, it only replaces what differs from the
+// host, and restarts the validator once if any of it did.
+type UpdateCommand struct {
+	InstallCommand
+}
+
+func (c *UpdateCommand) AddToPayload(p *runner.Payload) error {
+	{
+		r, err := assets.Open(assetsUpdate)
+
+		if err != nil {
+			return err
+		}
+
+		p.AddReader(runner.ScriptNameSteps, r)
+	}
+
+	return c.addValidatorFiles(p)
+}
+
 type UninstallCommand struct {
 	Firedancer
 }
@@ -216,7 +291,7 @@ func (u *UninstallCommand) Check() error {
 	u.Variant = &variant
 
 	pkgGrp := deb.Package{}.MakePackageGroup("svmkit-solana-cli")
-	pkgGrp.Add(deb.Package{Name: "svmkit-frankendancer", Version: u.Version})
+	pkgGrp.Add(deb.Package{Name: variant.PackageName(), Version: u.Version})
 
 	if err := u.UpdatePackageGroup(pkgGrp); err != nil {
 		return err
@@ -242,8 +317,10 @@ func (u *UninstallCommand) Env() *runner.EnvBuilder {
 	e := runner.NewEnvBuilder()
 
 	e.Merge(u.RunnerCommand.Env())
+	e.Merge(u.ServiceUser.Env())
 	e.Set("VALIDATOR_PACKAGE", u.Variant.PackageName())
 	e.Set("VALIDATOR_SERVICE", u.Variant.ServiceName())
+	e.Set("SETUP_SERVICE", u.Variant.SetupServiceName())
 
 	u.DeletionPolicy.Delete(&u.Firedancer, e)
 
@@ -265,6 +342,10 @@ func (u *UninstallCommand) AddToPayload(p *runner.Payload) error {
 		return err
 	}
 
+	if err := logging.AddToPayload(p); err != nil {
+		return err
+	}
+
 	return nil
 }
 
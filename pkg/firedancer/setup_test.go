@@ -0,0 +1,113 @@
+package firedancer
+
+import (
+	"testing"
+
+	"github.com/abklabs/svmkit/pkg/runner"
+	"github.com/abklabs/svmkit/pkg/runner/runnertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdatePayload(t *testing.T) {
+	fd := Firedancer{KeyPairs: KeyPairs{Identity: "[1]", VoteAccount: "[2]"}}
+
+	cmd := fd.Update()
+	require.NoError(t, cmd.Check())
+
+	files := runnertest.PayloadFiles(t, cmd)
+
+	for _, name := range []string{"config.toml", "svmkit-fd-setup.service", "svmkit-fd-validator.service", "validator-keypair.json", "vote-account-keypair.json"} {
+		assert.Contains(t, files, name)
+	}
+
+	update, err := assets.ReadFile(assetsUpdate)
+	require.NoError(t, err)
+
+	assert.Equal(t, string(update), files[runner.ScriptNameSteps])
+}
+
+func TestInstallRelease(t *testing.T) {
+	fd := Firedancer{
+		Version:  ptr("0.406.20113-1"),
+		KeyPairs: KeyPairs{Identity: "[1]", VoteAccount: "[2]"},
+		Config:   Config{Net: &ConfigNet{Interface: ptr("eth0")}},
+	}
+
+	cmd := &InstallCommand{Firedancer: fd}
+	assert.Error(t, cmd.Check())
+	assert.Equal(t, Release04, *cmd.Firedancer.Config.Release)
+
+	fd.Version = ptr("0.503.20214-1")
+	cmd = &InstallCommand{Firedancer: fd}
+	assert.NoError(t, cmd.Check())
+	assert.Equal(t, Release05, *cmd.Firedancer.Config.Release)
+}
+
+func TestVariants(t *testing.T) {
+	for _, tc := range []struct {
+		variant       *Variant
+		pkg           string
+		service       string
+		setupService  string
+		binary        string
+		otherServices string
+	}{
+		{nil, "svmkit-frankendancer", "svmkit-fd-validator", "svmkit-fd-setup", "/opt/frankendancer/bin/fdctl", "(svmkit-firedancer-validator svmkit-firedancer-setup)"},
+		{ptr(VariantFrankendancer), "svmkit-frankendancer", "svmkit-fd-validator", "svmkit-fd-setup", "/opt/frankendancer/bin/fdctl", "(svmkit-firedancer-validator svmkit-firedancer-setup)"},
+		{ptr(VariantFiredancer), "svmkit-firedancer", "svmkit-firedancer-validator", "svmkit-firedancer-setup", "/opt/firedancer/bin/firedancer", "(svmkit-fd-validator svmkit-fd-setup)"},
+	} {
+		fd := Firedancer{
+			Variant:  tc.variant,
+			Version:  ptr("0.503.20214-1"),
+			KeyPairs: KeyPairs{Identity: "[1]", VoteAccount: "[2]"},
+		}
+
+		assert.Equal(t, tc.service, fd.Properties().SystemdServiceName)
+
+		for _, cmd := range []runner.Command{fd.Install(), fd.Update()} {
+			require.NoError(t, cmd.Check())
+
+			env := cmd.Env().Map()
+			assert.Equal(t, tc.pkg, env["VALIDATOR_PACKAGE"])
+			assert.Equal(t, tc.service, env["VALIDATOR_SERVICE"])
+			assert.Equal(t, tc.setupService, env["SETUP_SERVICE"])
+			assert.Equal(t, tc.otherServices, env["OTHER_VARIANT_SERVICES"])
+			assert.Equal(t, "(svmkit-solana-cli "+tc.pkg+"=0.503.20214-1)", env["PACKAGE_LIST"])
+
+			files := runnertest.PayloadFiles(t, cmd)
+
+			require.Contains(t, files, tc.service+".service")
+			require.Contains(t, files, tc.setupService+".service")
+			assert.Contains(t, files[tc.service+".service"], "ExecStart="+tc.binary+" run --config /home/sol/config.toml")
+			assert.Contains(t, files[tc.service+".service"], "Requires="+tc.setupService+".service")
+			assert.Contains(t, files[tc.setupService+".service"], "ExecStart="+tc.binary+" configure init all --config /home/sol/config.toml")
+		}
+
+		uninstall := fd.Uninstall()
+		require.NoError(t, uninstall.Check())
+
+		env := uninstall.Env().Map()
+		assert.Equal(t, tc.pkg, env["VALIDATOR_PACKAGE"])
+		assert.Equal(t, tc.service, env["VALIDATOR_SERVICE"])
+		assert.Equal(t, tc.setupService, env["SETUP_SERVICE"])
+	}
+}
+
+func TestVariantConfig(t *testing.T) {
+	frankendancer := Config{Layout: &ConfigLayout{AgaveAffinity: ptr("16-31")}}
+	firedancer := Config{Layout: &ConfigLayout{ExecTileCount: ptr(4)}}
+
+	assert.NoError(t, frankendancer.CheckVariant(VariantFrankendancer))
+	assert.Error(t, frankendancer.CheckVariant(VariantFiredancer))
+	assert.NoError(t, firedancer.CheckVariant(VariantFiredancer))
+	assert.Error(t, firedancer.CheckVariant(VariantFrankendancer))
+
+	noAgave := Config{ExtraConfig: &[]string{"[development]\nno_agave = true\n"}}
+	assert.Error(t, noAgave.CheckVariant(VariantFiredancer))
+
+	fd := Firedancer{Variant: ptr(VariantFiredancer), KeyPairs: KeyPairs{Identity: "[1]", VoteAccount: "[2]"}, Config: frankendancer}
+	assert.Error(t, fd.Install().Check())
+
+	assert.Error(t, (&Firedancer{Variant: ptr(Variant("solana"))}).Uninstall().Check())
+}
@@ -0,0 +1,271 @@
+package firedancer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// TopologyLSCPUCommand prints the CPU topology for ParseLSCPU.
+	TopologyLSCPUCommand = "lscpu -p=CPU,Core,Socket,Node"
+
+	// TopologySysfsCommand prints the CPU topology for ParseSysfs,
+	// on hosts without lscpu.
+	TopologySysfsCommand = "grep -H . /sys/devices/system/cpu/cpu[0-9]*/topology/core_id /sys/devices/system/cpu/cpu[0-9]*/topology/physical_package_id; ls -d /sys/devices/system/cpu/cpu[0-9]*/node[0-9]* 2>/dev/null; true"
+)
+
+var sysfsRegexp = regexp.MustCompile(`^/sys/devices/system/cpu/cpu([0-9]+)/(?:topology/(core_id|physical_package_id):(-?[0-9]+)|node([0-9]+))$`)
+
+// CPU is a logical CPU, i.e. a hardware thread of a physical core.
+type CPU struct {
+	ID     int
+	Core   int
+	Socket int
+	Node   int
+}
+
+// Topology describes a host's online CPUs.
+type Topology struct {
+	CPUs []CPU
+}
+
+// physicalCore is a core's hardware threads, lowest numbered first.
+type physicalCore struct {
+	socket, core, node int
+	cpus               []int
+}
+
+// cores groups the CPUs by physical core, in order of their lowest
+// numbered CPU.
+func (t *Topology) cores() []physicalCore {
+	type key struct{ socket, core int }
+
+	index := map[key]int{}
+	var cores []physicalCore
+
+	cpus := slices.Clone(t.CPUs)
+	slices.SortFunc(cpus, func(a, b CPU) int { return a.ID - b.ID })
+
+	for _, c := range cpus {
+		k := key{c.Socket, c.Core}
+
+		i, ok := index[k]
+
+		if !ok {
+			i = len(cores)
+			index[k] = i
+			cores = append(cores, physicalCore{socket: c.Socket, core: c.Core, node: c.Node})
+		}
+
+		cores[i].cpus = append(cores[i].cpus, c.ID)
+	}
+
+	return cores
+}
+
+func (t *Topology) Check() error {
+	if len(t.CPUs) == 0 {
+		return fmt.Errorf("the CPU topology lists no CPUs")
+	}
+
+	seen := map[int]bool{}
+
+	for _, c := range t.CPUs {
+		if c.ID < 0 || c.Core < 0 || c.Socket < 0 || c.Node < 0 {
+			return fmt.Errorf("invalid topology for CPU %d", c.ID)
+		}
+
+		if seen[c.ID] {
+			return fmt.Errorf("CPU %d is listed more than once", c.ID)
+		}
+
+		seen[c.ID] = true
+	}
+
+	return nil
+}
+
+// ParseLSCPU parses the output of lscpu's parsable format, e.g. that
+// of TopologyLSCPUCommand.  Columns are found by the header lscpu
+// prints, and the Node column may be empty on hosts without NUMA.
+func ParseLSCPU(out string) (*Topology, error) {
+	columns := map[string]int{"CPU": 0, "Core": 1, "Socket": 2, "Node": 3}
+	t := &Topology{}
+
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+
+		if line == "" {
+			continue
+		}
+
+		if header, ok := strings.CutPrefix(line, "#"); ok {
+			fields := strings.Split(strings.TrimSpace(header), ",")
+
+			if slices.Contains(fields, "CPU") {
+				columns = map[string]int{}
+				for i, f := range fields {
+					columns[f] = i
+				}
+			}
+
+			continue
+		}
+
+		fields := strings.Split(line, ",")
+		values := map[string]int{}
+
+		for _, name := range []string{"CPU", "Core", "Socket", "Node"} {
+			i, ok := columns[name]
+
+			if !ok {
+				if name == "Node" {
+					continue
+				}
+
+				return nil, fmt.Errorf("lscpu output has no %s column", name)
+			}
+
+			if i >= len(fields) {
+				return nil, fmt.Errorf("invalid lscpu line '%s'", line)
+			}
+
+			if fields[i] == "" && name == "Node" {
+				continue
+			}
+
+			v, err := strconv.Atoi(fields[i])
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s in lscpu line '%s': %w", name, line, err)
+			}
+
+			values[name] = v
+		}
+
+		t.CPUs = append(t.CPUs, CPU{ID: values["CPU"], Core: values["Core"], Socket: values["Socket"], Node: values["Node"]})
+	}
+
+	if err := t.Check(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// ParseSysfs parses the output of TopologySysfsCommand.  CPUs without
+// a core or package, such as offline ones, are left out.
+func ParseSysfs(out string) (*Topology, error) {
+	type partial struct {
+		core, socket *int
+		node         int
+	}
+
+	cpus := map[int]*partial{}
+
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+
+		if line == "" {
+			continue
+		}
+
+		m := sysfsRegexp.FindStringSubmatch(line)
+
+		if m == nil {
+			return nil, fmt.Errorf("invalid sysfs topology line '%s'", line)
+		}
+
+		id, _ := strconv.Atoi(m[1])
+
+		p, ok := cpus[id]
+		if !ok {
+			p = &partial{}
+			cpus[id] = p
+		}
+
+		switch {
+		case m[4] != "":
+			p.node, _ = strconv.Atoi(m[4])
+		default:
+			v, _ := strconv.Atoi(m[3])
+
+			if v < 0 {
+				continue
+			}
+
+			if m[2] == "core_id" {
+				p.core = &v
+			} else {
+				p.socket = &v
+			}
+		}
+	}
+
+	t := &Topology{}
+
+	for id, p := range cpus {
+		if p.core == nil || p.socket == nil {
+			continue
+		}
+
+		t.CPUs = append(t.CPUs, CPU{ID: id, Core: *p.core, Socket: *p.socket, Node: p.node})
+	}
+
+	slices.SortFunc(t.CPUs, func(a, b CPU) int { return a.ID - b.ID })
+
+	if err := t.Check(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// GetTopology collects the host's CPU topology, from lscpu if it is
+// installed, or sysfs otherwise.
+func GetTopology(client *ssh.Client) (*Topology, error) {
+	if client == nil {
+		return nil, fmt.Errorf("SSH client cannot be nil")
+	}
+
+	out, err := runRemote(client, TopologyLSCPUCommand)
+
+	if err == nil {
+		return ParseLSCPU(out)
+	}
+
+	out, err = runRemote(client, TopologySysfsCommand)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseSysfs(out)
+}
+
+func runRemote(client *ssh.Client, cmd string) (out string, err error) {
+	session, err := client.NewSession()
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		if closeErr := session.Close(); closeErr != io.EOF {
+			err = errors.Join(err, closeErr)
+		}
+	}()
+
+	b, err := session.Output(cmd)
+	if err != nil {
+		return "", fmt.Errorf("remote '%s' failed: %w", cmd, err)
+	}
+
+	return string(b), nil
+}
@@ -0,0 +1,109 @@
+package firedancer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lscpuOutput renders lscpu -p output for a host numbering its CPUs
+// the way Linux does: each core's first thread, socket by socket,
+// then its second.  Each socket is its own NUMA node.
+func lscpuOutput(sockets, coresPerSocket, threads int) string {
+	var b strings.Builder
+
+	b.WriteString("# The following is the parsable format, which can be fed to other\n")
+	b.WriteString("# programs. Each different item in every column has an unique ID\n")
+	b.WriteString("# starting usually from zero.\n")
+	b.WriteString("# CPU,Core,Socket,Node\n")
+
+	cpus := sockets * coresPerSocket
+
+	for thread := 0; thread < threads; thread++ {
+		for core := 0; core < cpus; core++ {
+			fmt.Fprintf(&b, "%d,%d,%d,%d\n", thread*cpus+core, core, core/coresPerSocket, core/coresPerSocket)
+		}
+	}
+
+	return b.String()
+}
+
+func TestParseLSCPU(t *testing.T) {
+	topo, err := ParseLSCPU(lscpuOutput(2, 2, 2))
+	require.NoError(t, err)
+
+	assert.Equal(t, []CPU{
+		{ID: 0, Core: 0, Socket: 0, Node: 0},
+		{ID: 1, Core: 1, Socket: 0, Node: 0},
+		{ID: 2, Core: 2, Socket: 1, Node: 1},
+		{ID: 3, Core: 3, Socket: 1, Node: 1},
+		{ID: 4, Core: 0, Socket: 0, Node: 0},
+		{ID: 5, Core: 1, Socket: 0, Node: 0},
+		{ID: 6, Core: 2, Socket: 1, Node: 1},
+		{ID: 7, Core: 3, Socket: 1, Node: 1},
+	}, topo.CPUs)
+
+	cores := topo.cores()
+	require.Len(t, cores, 4)
+	assert.Equal(t, []int{0, 4}, cores[0].cpus)
+	assert.Equal(t, []int{3, 7}, cores[3].cpus)
+
+	// Plain lscpu -p, on a VM without NUMA.
+	topo, err = ParseLSCPU("# CPU,Core,Socket,Node,,L1d,L1i,L2,L3\n0,0,0,,,0,0,0,0\n1,0,0,,,0,0,0,0\n")
+	require.NoError(t, err)
+	assert.Equal(t, []CPU{{ID: 0}, {ID: 1}}, topo.CPUs)
+
+	for _, out := range []string{
+		"",
+		"# CPU,Core,Socket,Node\n0,0,x,0\n",
+		"# CPU,Core,Socket,Node\n0,0\n",
+		"# CPU,Socket,Node\n0,0,0\n",
+		"# CPU,Core,Socket,Node\n0,0,0,0\n0,1,0,0\n",
+	} {
+		_, err := ParseLSCPU(out)
+		assert.Error(t, err, out)
+	}
+}
+
+func TestParseSysfs(t *testing.T) {
+	out := `/sys/devices/system/cpu/cpu0/topology/core_id:0
+/sys/devices/system/cpu/cpu1/topology/core_id:1
+/sys/devices/system/cpu/cpu2/topology/core_id:0
+/sys/devices/system/cpu/cpu3/topology/core_id:-1
+/sys/devices/system/cpu/cpu10/topology/core_id:1
+/sys/devices/system/cpu/cpu0/topology/physical_package_id:0
+/sys/devices/system/cpu/cpu1/topology/physical_package_id:0
+/sys/devices/system/cpu/cpu2/topology/physical_package_id:0
+/sys/devices/system/cpu/cpu3/topology/physical_package_id:-1
+/sys/devices/system/cpu/cpu10/topology/physical_package_id:1
+/sys/devices/system/cpu/cpu0/node0
+/sys/devices/system/cpu/cpu1/node0
+/sys/devices/system/cpu/cpu2/node0
+/sys/devices/system/cpu/cpu10/node1
+`
+
+	topo, err := ParseSysfs(out)
+	require.NoError(t, err)
+
+	assert.Equal(t, []CPU{
+		{ID: 0, Core: 0, Socket: 0, Node: 0},
+		{ID: 1, Core: 1, Socket: 0, Node: 0},
+		{ID: 2, Core: 0, Socket: 0, Node: 0},
+		{ID: 10, Core: 1, Socket: 1, Node: 1},
+	}, topo.CPUs)
+
+	// Core IDs are per package in sysfs.
+	assert.Len(t, topo.cores(), 3)
+
+	for _, out := range []string{
+		"",
+		"/sys/devices/system/cpu/cpu0/topology/thread_siblings_list:0-1\n",
+		"/sys/devices/system/cpu/cpu3/topology/core_id:-1\n",
+	} {
+		_, err := ParseSysfs(out)
+		assert.Error(t, err, out)
+	}
+}
@@ -52,10 +52,66 @@ func (v Variant) PackageName() string {
 
 func (v Variant) ServiceName() string {
 	switch v {
-	case VariantFrankendancer, VariantFiredancer:
+	case VariantFrankendancer:
 		return "svmkit-fd-validator"
+	case VariantFiredancer:
+		return "svmkit-firedancer-validator"
 	default:
 		// XXX - mirroring behavior of agave/variant.go
 		return ""
 	}
 }
+
+// SetupServiceName is the oneshot service that prepares the machine
+// for the validator before it starts.
+func (v Variant) SetupServiceName() string {
+	switch v {
+	case VariantFrankendancer:
+		return "svmkit-fd-setup"
+	case VariantFiredancer:
+		return "svmkit-firedancer-setup"
+	default:
+		return ""
+	}
+}
+
+// OtherServiceNames returns the validator and setup services of every
+// other variant, which must not run alongside this one.
+func (v Variant) OtherServiceNames() []string {
+	var res []string
+
+	for _, o := range []Variant{VariantFrankendancer, VariantFiredancer} {
+		if o != v {
+			res = append(res, o.ServiceName(), o.SetupServiceName())
+		}
+	}
+
+	return res
+}
+
+// BinaryPath is where the variant's package installs the binary that
+// both sets up the machine and runs the validator.
+func (v Variant) BinaryPath() string {
+	switch v {
+	case VariantFrankendancer:
+		return "/opt/frankendancer/bin/fdctl"
+	case VariantFiredancer:
+		return "/opt/firedancer/bin/firedancer"
+	default:
+		return ""
+	}
+}
+
+// Description names the variant in its systemd units.  Frankendancer
+// keeps its original name, so that updating doesn't rewrite, and
+// restart, its units.
+func (v Variant) Description() string {
+	switch v {
+	case VariantFrankendancer:
+		return "FD"
+	case VariantFiredancer:
+		return "Firedancer"
+	default:
+		return ""
+	}
+}
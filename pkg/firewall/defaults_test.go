@@ -14,3 +14,12 @@ func TestNewDefaultFirewall(t *testing.T) {
 	require.NotNil(t, firewall, "NewDefaultFirewall should return a non-nil Firewall struct")
 
 }
+
+func TestFirewallParamsAllow(t *testing.T) {
+	params := FirewallParams{AllowPorts: []string{"ssh", "7999/tcp"}}
+
+	params.Allow("7999/tcp", "from 10.0.0.0/8 to any port 8080 proto tcp")
+	params.Allow()
+
+	require.Equal(t, []string{"ssh", "7999/tcp", "from 10.0.0.0/8 to any port 8080 proto tcp"}, params.AllowPorts)
+}
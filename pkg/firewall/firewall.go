@@ -1,6 +1,8 @@
 package firewall
 
 import (
+	"slices"
+
 	"github.com/abklabs/svmkit/pkg/runner"
 	"github.com/abklabs/svmkit/pkg/runner/deb"
 
@@ -38,6 +40,16 @@ type FirewallParams struct {
 	AllowPorts []string `pulumi:"allowPorts" toml:"allowPorts"`
 }
 
+// Allow adds ufw allow rules, such as those firedancer.Firedancer's
+// FirewallRules returns, skipping any already present.
+func (p *FirewallParams) Allow(rules ...string) {
+	for _, r := range rules {
+		if !slices.Contains(p.AllowPorts, r) {
+			p.AllowPorts = append(p.AllowPorts, r)
+		}
+	}
+}
+
 type Firewall struct {
 	runner.RunnerCommand
 	Params FirewallParams `pulumi:"params" toml:"params"`
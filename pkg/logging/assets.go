@@ -0,0 +1,12 @@
+package logging
+
+import (
+	"embed"
+)
+
+//go:embed assets
+var assets embed.FS
+
+const (
+	assetsLib = "assets/lib.sh"
+)
@@ -0,0 +1,35 @@
+# -*- mode: shell-script -*-
+# shellcheck shell=bash
+
+# Install (or remove) a managed file, reporting whether it changed.
+logging::install-file() {
+    local src=$1 dst=$2 mode=$3
+
+    if [[ -f "$src" ]]; then
+        cmp -s "$src" "$dst" && return 1
+        svmkit::sudo mkdir -p "$(dirname "$dst")"
+        svmkit::sudo install -m "$mode" -o root -g root "$src" "$dst"
+    else
+        [[ -e "$dst" ]] || return 1
+        svmkit::sudo rm -f "$dst"
+    fi
+}
+
+# Install the logrotate policy and unit logging settings rendered for
+# SERVICE.
+logging::install() {
+    local service=$1
+
+    logging::install-file logging/logrotate.conf "/etc/logrotate.d/$service" 644 || true
+
+    if logging::install-file logging/unit.conf "/etc/systemd/system/$service.service.d/50-logging.conf" 644; then
+        svmkit::sudo systemctl daemon-reload
+    fi
+}
+
+# Remove everything logging::install put in place for SERVICE.
+logging::uninstall() {
+    local service=$1
+
+    svmkit::sudo rm -f "/etc/logrotate.d/$service" "/etc/systemd/system/$service.service.d/50-logging.conf"
+}
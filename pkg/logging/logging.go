@@ -0,0 +1,349 @@
+package logging
+
+import (
+	_ "embed"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"dario.cat/mergo"
+	"github.com/BurntSushi/toml"
+	"github.com/abklabs/svmkit/pkg/runner"
+	"github.com/pulumi/pulumi-go-provider/infer"
+)
+
+//go:embed defaults/defaults.toml
+var defaultsToml []byte
+
+var (
+	logRotateSizeRegexp = regexp.MustCompile(`^[0-9]+[kMG]?$`)
+	journaldSizeRegexp  = regexp.MustCompile(`^[0-9]+[KMGT]?$`)
+	signalRegexp        = regexp.MustCompile(`^[A-Z][A-Z0-9]*$`)
+)
+
+// Profile selects the defaults for a kind of service.
+type Profile string
+
+const (
+	ProfileValidator  Profile = "validator"
+	ProfileFiredancer Profile = "firedancer"
+	ProfileFaucet     Profile = "faucet"
+	ProfileExplorer   Profile = "explorer"
+	ProfileWatchtower Profile = "watchtower"
+)
+
+type RotateMethod string
+
+const (
+	RotateMethodCopyTruncate RotateMethod = "copytruncate"
+	RotateMethodSignal       RotateMethod = "signal"
+)
+
+func (RotateMethod) Values() []infer.EnumValue[RotateMethod] {
+	return []infer.EnumValue[RotateMethod]{
+		{
+			Name:        string(RotateMethodCopyTruncate),
+			Value:       RotateMethodCopyTruncate,
+			Description: "Copy the log file and truncate it in place",
+		},
+		{
+			Name:        string(RotateMethodSignal),
+			Value:       RotateMethodSignal,
+			Description: "Move the log file aside and signal the service to reopen it",
+		},
+	}
+}
+
+func (m RotateMethod) Check() error {
+	switch m {
+	case RotateMethodCopyTruncate, RotateMethodSignal:
+	default:
+		return fmt.Errorf("unknown log rotation method '%s'", m)
+	}
+
+	return nil
+}
+
+type LogRotate struct {
+	// Paths are rotated in addition to the log file the component
+	// itself is configured to write.
+	Paths *[]string `pulumi:"paths,optional" toml:"paths,omitempty"`
+
+	// Size is the size a log file is rotated at, e.g. "500M".
+	Size     *string       `pulumi:"size,optional" toml:"size,omitempty"`
+	Rotate   *int          `pulumi:"rotate,optional" toml:"rotate,omitempty"`
+	Compress *bool         `pulumi:"compress,optional" toml:"compress,omitempty"`
+	Method   *RotateMethod `pulumi:"method,optional" toml:"method,omitempty"`
+	// Signal is sent to the service's main process after rotation
+	// when Method is "signal", e.g. "USR1".
+	Signal *string `pulumi:"signal,optional" toml:"signal,omitempty"`
+}
+
+func (l *LogRotate) Check() error {
+	if l.Paths != nil {
+		for _, p := range *l.Paths {
+			if !path.IsAbs(p) || strings.ContainsAny(p, " \t\n{}") {
+				return fmt.Errorf("invalid log path '%s'", p)
+			}
+		}
+	}
+
+	if l.Size != nil && !logRotateSizeRegexp.MatchString(*l.Size) {
+		return fmt.Errorf("invalid log rotation size '%s'", *l.Size)
+	}
+
+	if l.Rotate != nil && *l.Rotate < 0 {
+		return fmt.Errorf("log rotation count must not be negative")
+	}
+
+	if l.Method != nil {
+		if err := l.Method.Check(); err != nil {
+			return err
+		}
+
+		if *l.Method == RotateMethodSignal && l.Signal == nil {
+			return fmt.Errorf("log rotation by signal needs a signal")
+		}
+	}
+
+	if l.Signal != nil && !signalRegexp.MatchString(*l.Signal) {
+		return fmt.Errorf("invalid signal '%s'", *l.Signal)
+	}
+
+	return nil
+}
+
+// Render returns the logrotate policy for a service's log files.
+func (l *LogRotate) Render(service string, paths []string) []byte {
+	var b strings.Builder
+
+	b.WriteString(strings.Join(paths, " ") + " {\n")
+
+	if l.Size != nil {
+		b.WriteString("    size " + *l.Size + "\n")
+	}
+
+	if l.Rotate != nil {
+		b.WriteString("    rotate " + strconv.Itoa(*l.Rotate) + "\n")
+	}
+
+	if l.Compress != nil && *l.Compress {
+		b.WriteString("    compress\n")
+		b.WriteString("    delaycompress\n")
+	}
+
+	b.WriteString("    missingok\n")
+	b.WriteString("    notifempty\n")
+
+	if l.Method != nil && *l.Method == RotateMethodSignal {
+		b.WriteString("    sharedscripts\n")
+		b.WriteString("    postrotate\n")
+		fmt.Fprintf(&b, "        systemctl kill --kill-whom=main --signal=%s %s.service || true\n", *l.Signal, service)
+		b.WriteString("    endscript\n")
+	} else {
+		b.WriteString("    copytruncate\n")
+	}
+
+	b.WriteString("}\n")
+
+	return []byte(b.String())
+}
+
+// Journald holds the journal settings for a component's own service.
+// The journal's size and retention limits are global to the machine,
+// and are set with JournalLimits on machine.Machine instead.
+type Journald struct {
+	RateLimitIntervalSec *int `pulumi:"rateLimitIntervalSec,optional" toml:"rateLimitIntervalSec,omitempty"`
+	RateLimitBurst       *int `pulumi:"rateLimitBurst,optional" toml:"rateLimitBurst,omitempty"`
+}
+
+func (j *Journald) Check() error {
+	for _, v := range []*int{j.RateLimitIntervalSec, j.RateLimitBurst} {
+		if v != nil && *v < 0 {
+			return fmt.Errorf("journald rate limits must not be negative")
+		}
+	}
+
+	return nil
+}
+
+// UnitConf renders the service drop-in carrying its rate limits, or
+// nil if none are set.
+func (j *Journald) UnitConf() []byte {
+	var b strings.Builder
+
+	if v := j.RateLimitIntervalSec; v != nil {
+		b.WriteString("LogRateLimitIntervalSec=" + strconv.Itoa(*v) + "s\n")
+	}
+
+	if v := j.RateLimitBurst; v != nil {
+		b.WriteString("LogRateLimitBurst=" + strconv.Itoa(*v) + "\n")
+	}
+
+	if b.Len() == 0 {
+		return nil
+	}
+
+	return []byte("[Service]\n" + b.String())
+}
+
+// JournalLimits holds the journal's size and retention limits, which
+// apply to the whole machine.
+type JournalLimits struct {
+	SystemMaxUse      *string `pulumi:"systemMaxUse,optional"`
+	SystemMaxFileSize *string `pulumi:"systemMaxFileSize,optional"`
+	MaxRetentionSec   *string `pulumi:"maxRetentionSec,optional"`
+}
+
+func (j *JournalLimits) Check() error {
+	for _, v := range []*string{j.SystemMaxUse, j.SystemMaxFileSize} {
+		if v != nil && !journaldSizeRegexp.MatchString(*v) {
+			return fmt.Errorf("invalid journald size '%s'", *v)
+		}
+	}
+
+	if v := j.MaxRetentionSec; v != nil && (*v == "" || strings.ContainsAny(*v, "\n")) {
+		return fmt.Errorf("invalid journald retention '%s'", *v)
+	}
+
+	return nil
+}
+
+// JournaldConf renders the journald.conf drop-in, or nil if no limits
+// are set.
+func (j *JournalLimits) JournaldConf() []byte {
+	var b strings.Builder
+
+	set := func(k string, v *string) {
+		if v != nil {
+			b.WriteString(k + "=" + *v + "\n")
+		}
+	}
+
+	set("SystemMaxUse", j.SystemMaxUse)
+	set("SystemMaxFileSize", j.SystemMaxFileSize)
+	set("MaxRetentionSec", j.MaxRetentionSec)
+
+	if b.Len() == 0 {
+		return nil
+	}
+
+	return []byte("[Journal]\n" + b.String())
+}
+
+type Config struct {
+	LogRotate *LogRotate `pulumi:"logRotate,optional" toml:"logRotate,omitempty"`
+	Journald  *Journald  `pulumi:"journald,optional" toml:"journald,omitempty"`
+}
+
+func NewDefaultConfig(profile Profile) (*Config, error) {
+	var defaults map[Profile]Config
+
+	if err := toml.Unmarshal(defaultsToml, &defaults); err != nil {
+		return nil, err
+	}
+
+	c, ok := defaults[profile]
+
+	if !ok {
+		return nil, fmt.Errorf("unknown logging profile '%s'", profile)
+	}
+
+	return &c, nil
+}
+
+func (c *Config) Merge(other *Config) error {
+	if other == nil {
+		return nil
+	}
+
+	return mergo.Merge(c, other, mergo.WithOverride)
+}
+
+// Resolve returns the profile's defaults with c merged over them.
+func (c *Config) Resolve(profile Profile) (*Config, error) {
+	res, err := NewDefaultConfig(profile)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := res.Merge(c); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *Config) Check() error {
+	if l := c.LogRotate; l != nil {
+		if err := l.Check(); err != nil {
+			return err
+		}
+	}
+
+	if j := c.Journald; j != nil {
+		if err := j.Check(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CheckFor resolves c against profile's defaults, and checks the
+// result.
+func (c *Config) CheckFor(profile Profile) error {
+	res, err := c.Resolve(profile)
+
+	if err != nil {
+		return err
+	}
+
+	return res.Check()
+}
+
+// AddToPayload adds the logging library, for scripts that only need
+// to remove a service's logging configuration.
+func AddToPayload(p *runner.Payload) error {
+	lib, err := assets.Open(assetsLib)
+
+	if err != nil {
+		return err
+	}
+
+	p.AddReader("logging-lib.sh", lib)
+
+	return nil
+}
+
+// AddToPayload adds the logging library and the files it installs
+// for service.  logFiles are the log files the service is configured
+// to write, which are rotated along with any extra LogRotate paths.
+func (c *Config) AddToPayload(p *runner.Payload, service string, logFiles ...string) error {
+	if err := AddToPayload(p); err != nil {
+		return err
+	}
+
+	if l := c.LogRotate; l != nil {
+		paths := append([]string{}, logFiles...)
+
+		if l.Paths != nil {
+			paths = append(paths, *l.Paths...)
+		}
+
+		if len(paths) != 0 {
+			p.NewBuffer(runner.PayloadFile{Path: "logging/logrotate.conf"}, l.Render(service, paths))
+		}
+	}
+
+	if j := c.Journald; j != nil {
+		if b := j.UnitConf(); b != nil {
+			p.NewBuffer(runner.PayloadFile{Path: "logging/unit.conf"}, b)
+		}
+	}
+
+	return nil
+}
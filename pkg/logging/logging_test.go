@@ -0,0 +1,111 @@
+package logging
+
+import (
+	"testing"
+
+	"github.com/abklabs/svmkit/pkg/runner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ptr[T any](in T) *T {
+	return &in
+}
+
+func TestNewDefaultConfig(t *testing.T) {
+	for _, profile := range []Profile{ProfileValidator, ProfileFiredancer, ProfileFaucet, ProfileExplorer, ProfileWatchtower} {
+		c, err := NewDefaultConfig(profile)
+		require.NoError(t, err, profile)
+		assert.Nil(t, c.Check(), profile)
+	}
+
+	c, err := NewDefaultConfig(ProfileValidator)
+	require.NoError(t, err)
+	require.NotNil(t, c.LogRotate)
+	assert.Equal(t, RotateMethodSignal, *c.LogRotate.Method)
+	assert.Equal(t, "USR1", *c.LogRotate.Signal)
+
+	_, err = NewDefaultConfig("bogus")
+	assert.Error(t, err)
+}
+
+func TestResolve(t *testing.T) {
+	c := Config{
+		LogRotate: &LogRotate{Rotate: ptr(3)},
+		Journald:  &Journald{RateLimitBurst: ptr(50000)},
+	}
+
+	res, err := c.Resolve(ProfileValidator)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, *res.LogRotate.Rotate)
+	assert.Equal(t, "1G", *res.LogRotate.Size)
+	assert.Equal(t, 50000, *res.Journald.RateLimitBurst)
+	assert.Equal(t, 30, *res.Journald.RateLimitIntervalSec)
+}
+
+func TestLogRotateRender(t *testing.T) {
+	l := LogRotate{
+		Size:     ptr("1G"),
+		Rotate:   ptr(7),
+		Compress: ptr(true),
+		Method:   ptr(RotateMethodSignal),
+		Signal:   ptr("USR1"),
+	}
+
+	assert.Nil(t, l.Check())
+	assert.Equal(t, `/home/sol/log/validator.log {
+    size 1G
+    rotate 7
+    compress
+    delaycompress
+    missingok
+    notifempty
+    sharedscripts
+    postrotate
+        systemctl kill --kill-whom=main --signal=USR1 svmkit-agave-validator.service || true
+    endscript
+}
+`, string(l.Render("svmkit-agave-validator", []string{"/home/sol/log/validator.log"})))
+
+	l = LogRotate{Method: ptr(RotateMethodCopyTruncate)}
+	assert.Equal(t, "/a.log /b.log {\n    missingok\n    notifempty\n    copytruncate\n}\n", string(l.Render("x", []string{"/a.log", "/b.log"})))
+}
+
+func TestChecks(t *testing.T) {
+	assert.Error(t, (&LogRotate{Method: ptr(RotateMethodSignal)}).Check())
+	assert.Error(t, (&LogRotate{Size: ptr("1GB")}).Check())
+	assert.Error(t, (&LogRotate{Paths: &[]string{"relative.log"}}).Check())
+	assert.Error(t, (&Journald{RateLimitBurst: ptr(-1)}).Check())
+	assert.Error(t, (&JournalLimits{SystemMaxUse: ptr("lots")}).Check())
+	assert.Error(t, (&JournalLimits{MaxRetentionSec: ptr("")}).Check())
+
+	var none *Config
+	assert.NoError(t, none.CheckFor(ProfileValidator))
+	assert.Error(t, none.CheckFor("unknown"))
+	assert.Error(t, (&Config{Journald: &Journald{RateLimitBurst: ptr(-1)}}).CheckFor(ProfileValidator))
+}
+
+func TestAddToPayload(t *testing.T) {
+	c, err := NewDefaultConfig(ProfileValidator)
+	require.NoError(t, err)
+
+	p := &runner.Payload{}
+	require.NoError(t, c.AddToPayload(p, "svmkit-agave-validator", "/home/sol/log/validator.log"))
+
+	var paths []string
+	for _, f := range p.Files {
+		paths = append(paths, f.Path)
+	}
+
+	assert.Equal(t, []string{"logging-lib.sh", "logging/logrotate.conf", "logging/unit.conf"}, paths)
+	assert.Equal(t, "[Service]\nLogRateLimitIntervalSec=30s\nLogRateLimitBurst=20000\n", string(c.Journald.UnitConf()))
+}
+
+func TestJournaldConf(t *testing.T) {
+	assert.Nil(t, (&JournalLimits{}).JournaldConf())
+
+	j := JournalLimits{SystemMaxUse: ptr("4G"), SystemMaxFileSize: ptr("256M")}
+	assert.Nil(t, j.Check())
+	assert.Equal(t, "[Journal]\nSystemMaxUse=4G\nSystemMaxFileSize=256M\n", string(j.JournaldConf()))
+}
@@ -3,4 +3,10 @@ package apt
 type Config struct {
 	Sources               *Sources `pulumi:"sources,optional"`
 	ExcludeDefaultSources *bool    `pulumi:"excludeDefaultSources,optional"`
+
+	// KeepConflictingLegacySources leaves one-line entries in
+	// /etc/apt/sources.list and sources.list.d/*.list alone, even
+	// when they name the same repository and suite as one of our
+	// sources.  By default such entries are commented out.
+	KeepConflictingLegacySources *bool `pulumi:"keepConflictingLegacySources,optional"`
 }
@@ -0,0 +1,121 @@
+package apt
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// oneLineExtraFields maps one-line options that have no typed field
+// on Source to the deb822 field names they're carried as in
+// ExtraLines.
+var oneLineExtraFields = map[string]string{
+	"lang":    "Languages",
+	"target":  "Targets",
+	"pdiffs":  "PDiffs",
+	"by-hash": "By-Hash",
+}
+
+func (s Source) oneLineOptions() ([]string, error) {
+	var opts []string
+
+	appendBool := func(k string, v *bool) {
+		if v != nil {
+			opts = append(opts, k+"="+map[bool]string{true: "yes", false: "no"}[*v])
+		}
+	}
+
+	appendInt := func(k string, v *int) {
+		if v != nil {
+			opts = append(opts, k+"="+strconv.Itoa(*v))
+		}
+	}
+
+	if s.Architectures != nil {
+		opts = append(opts, "arch="+strings.Join(*s.Architectures, ","))
+	}
+
+	if t := s.SignedBy; t != nil {
+		if t.PublicKey != nil {
+			return nil, errors.New("an embedded signed-by public key can't be expressed in one-line format")
+		}
+
+		if t.Paths != nil {
+			opts = append(opts, "signed-by="+strings.Join(*t.Paths, ","))
+		}
+	}
+
+	appendBool("allow-downgrade-to-insecure", s.AllowDowngradeToInsecure)
+	appendBool("allow-insecure", s.AllowInsecure)
+	appendBool("allow-weak", s.AllowWeak)
+	appendBool("check-date", s.CheckDate)
+	appendBool("check-valid-until", s.CheckValidUntil)
+	appendInt("date-max-future", s.DateMaxFuture)
+
+	if s.InReleasePath != nil {
+		opts = append(opts, "inrelease-path="+*s.InReleasePath)
+	}
+
+	if s.Snapshot != nil {
+		opts = append(opts, "snapshot="+*s.Snapshot)
+	}
+
+	appendBool("trusted", s.Trusted)
+	appendInt("valid-until-max", s.ValidUntilMax)
+	appendInt("valid-until-min", s.ValidUntilMin)
+
+	if s.ExtraLines != nil {
+		for _, line := range *s.ExtraLines {
+			name, value, _ := strings.Cut(line, ":")
+
+			found := false
+
+			for k, v := range oneLineExtraFields {
+				if strings.EqualFold(v, name) {
+					opts = append(opts, k+"="+strings.Join(strings.Fields(value), ","))
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				return nil, fmt.Errorf("extra line %q can't be expressed in one-line format", line)
+			}
+		}
+	}
+
+	return opts, nil
+}
+
+// OneLines renders the source in the legacy one-line format.  A
+// deb822 stanza may name several types, URIs and suites, so one line
+// is produced for each combination.
+func (s Source) OneLines() ([]string, error) {
+	opts, err := s.oneLineOptions()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var res []string
+
+	for _, kind := range s.Types {
+		for _, uri := range s.URIs {
+			for _, suite := range s.Suites {
+				words := []string{kind}
+
+				if len(opts) != 0 {
+					words = append(words, "["+strings.Join(opts, " ")+"]")
+				}
+
+				words = append(words, uri, suite)
+				words = append(words, s.Components...)
+
+				res = append(res, strings.Join(words, " "))
+			}
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,35 @@
+package apt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSourceOneLines(t *testing.T) {
+	s := Source{
+		Types:      []string{"deb", "deb-src"},
+		URIs:       []string{"https://deb.debian.org/debian"},
+		Suites:     []string{"bookworm", "bookworm-updates"},
+		Components: []string{"main"},
+		CheckDate:  ptr(true),
+	}
+
+	lines, err := s.OneLines()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"deb [check-date=yes] https://deb.debian.org/debian bookworm main",
+		"deb [check-date=yes] https://deb.debian.org/debian bookworm-updates main",
+		"deb-src [check-date=yes] https://deb.debian.org/debian bookworm main",
+		"deb-src [check-date=yes] https://deb.debian.org/debian bookworm-updates main",
+	}, lines)
+
+	s.SignedBy = &SignedBy{PublicKey: ptr("a key")}
+	_, err = s.OneLines()
+	assert.Error(t, err)
+
+	s.SignedBy = nil
+	s.ExtraLines = &[]string{"Enabled: no"}
+	_, err = s.OneLines()
+	assert.Error(t, err)
+}
@@ -0,0 +1,12 @@
+package disk
+
+import (
+	"embed"
+)
+
+//go:embed assets
+var assets embed.FS
+
+const (
+	assetsLib = "assets/lib.sh"
+)
@@ -0,0 +1,144 @@
+# -*- mode: shell-script -*-
+# shellcheck shell=bash
+
+# Print the filesystem type on a device, or nothing if it has none.
+disk::fstype() {
+    svmkit::sudo blkid -p -s TYPE -o value "$1" 2>/dev/null || true
+}
+
+# Make sure a device carries no filesystem, RAID or LVM signature, or
+# partition table.  Existing signatures are wiped if DISK_FORCE is
+# set, otherwise we refuse to go any further.
+disk::check-device() {
+    local dev=$1
+
+    [[ -b "$dev" ]] || log::fatal "'$dev' is not a block device"
+
+    if [[ -n "$(svmkit::sudo wipefs --noheadings "$dev")" ]]; then
+        if [[ "$DISK_FORCE" != "true" ]]; then
+            log::fatal "'$dev' already contains data - set force on volume '$DISK_NAME' to reformat it"
+        fi
+
+        log::warn "wiping existing signatures from '$dev'"
+        svmkit::sudo wipefs --all "$dev"
+    fi
+}
+
+# Set DISK_TARGET to the block device the volume's filesystem lives
+# on, assembling it from DISK_DEVICES if it doesn't exist yet.
+disk::assemble() {
+    local dev vg
+
+    case "$DISK_ASSEMBLY" in
+    none)
+        DISK_TARGET=${DISK_DEVICES[0]}
+        ;;
+    raid0)
+        DISK_TARGET=/dev/md/svmkit-$DISK_NAME
+
+        [[ -e "$DISK_TARGET" ]] && return 0
+
+        for dev in "${DISK_DEVICES[@]}"; do
+            disk::check-device "$dev"
+        done
+
+        svmkit::sudo mdadm --create "$DISK_TARGET" --run --level=0 --raid-devices="${#DISK_DEVICES[@]}" "${DISK_DEVICES[@]}"
+        svmkit::sudo mdadm --detail --brief "$DISK_TARGET" | svmkit::sudo tee -a /etc/mdadm/mdadm.conf >/dev/null
+        svmkit::sudo update-initramfs -u
+        ;;
+    lvm)
+        vg=svmkit-$DISK_NAME
+        DISK_TARGET=/dev/$vg/data
+
+        svmkit::sudo vgs "$vg" >/dev/null 2>&1 && return 0
+
+        for dev in "${DISK_DEVICES[@]}"; do
+            disk::check-device "$dev"
+        done
+
+        svmkit::sudo pvcreate "${DISK_DEVICES[@]}"
+        svmkit::sudo vgcreate "$vg" "${DISK_DEVICES[@]}"
+        svmkit::sudo lvcreate --yes --extents 100%FREE --stripes "${#DISK_DEVICES[@]}" --name data "$vg"
+        ;;
+    *)
+        log::fatal "unknown disk assembly '$DISK_ASSEMBLY'"
+        ;;
+    esac
+}
+
+# Create the volume's filesystem, unless the target already carries
+# one of the right type.
+disk::format() {
+    local fstype
+
+    fstype=$(disk::fstype "$DISK_TARGET")
+
+    [[ "$fstype" = "$DISK_FS" ]] && return 0
+
+    disk::check-device "$DISK_TARGET"
+
+    case "$DISK_FS" in
+    ext4)
+        svmkit::sudo mkfs.ext4 -q -F "$DISK_TARGET"
+        ;;
+    xfs)
+        svmkit::sudo mkfs.xfs -q -f "$DISK_TARGET"
+        ;;
+    *)
+        log::fatal "unknown filesystem '$DISK_FS'"
+        ;;
+    esac
+}
+
+# Point the volume's fstab entry at the filesystem's UUID, replacing
+# any other entry for the same mount point, and mount it.
+disk::mount() {
+    local uuid tmp
+
+    uuid=$(svmkit::sudo blkid -s UUID -o value "$DISK_TARGET")
+
+    [[ -n "$uuid" ]] || log::fatal "couldn't find the UUID of '$DISK_TARGET'"
+
+    tmp=$(mktemp)
+
+    awk -v mp="$DISK_MOUNT_POINT" '/^[[:space:]]*#/ || $2 != mp { print }' /etc/fstab >"$tmp"
+    printf 'UUID=%s %s %s %s 0 2\n' "$uuid" "$DISK_MOUNT_POINT" "$DISK_FS" "$DISK_MOUNT_OPTIONS" >>"$tmp"
+
+    if ! cmp -s /etc/fstab "$tmp"; then
+        svmkit::sudo cp "$tmp" /etc/fstab
+        svmkit::sudo systemctl daemon-reload
+    fi
+
+    rm -f "$tmp"
+
+    svmkit::sudo mkdir -p "$DISK_MOUNT_POINT"
+
+    if mountpoint -q "$DISK_MOUNT_POINT"; then
+        svmkit::sudo mount -o remount "$DISK_MOUNT_POINT"
+    else
+        svmkit::sudo mount "$DISK_MOUNT_POINT"
+    fi
+}
+
+disk::provision() {
+    local owner group
+
+    # shellcheck disable=SC1090
+    source "$1"
+
+    owner=${DISK_OWNER:-$SVMKIT_USER}
+    group=${DISK_GROUP:-$SVMKIT_GROUP}
+
+    if [[ ! -v DISK_OWNER ]]; then
+        create-sol-user
+    fi
+
+    svmkit::flock::start
+    disk::assemble
+    disk::format
+    disk::mount
+    svmkit::flock::end
+
+    svmkit::sudo chown "$owner:$group" "$DISK_MOUNT_POINT"
+    svmkit::sudo chmod "${DISK_MODE:-750}" "$DISK_MOUNT_POINT"
+}
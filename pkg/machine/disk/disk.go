@@ -0,0 +1,273 @@
+package disk
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/abklabs/svmkit/pkg/runner"
+	"github.com/abklabs/svmkit/pkg/runner/deb"
+	"github.com/pulumi/pulumi-go-provider/infer"
+)
+
+type Assembly string
+
+const (
+	AssemblyNone  Assembly = "none"
+	AssemblyRAID0 Assembly = "raid0"
+	AssemblyLVM   Assembly = "lvm"
+)
+
+func (Assembly) Values() []infer.EnumValue[Assembly] {
+	return []infer.EnumValue[Assembly]{
+		{
+			Name:        string(AssemblyNone),
+			Value:       AssemblyNone,
+			Description: "Use a single device as-is",
+		},
+		{
+			Name:        string(AssemblyRAID0),
+			Value:       AssemblyRAID0,
+			Description: "Stripe the devices together with an mdadm RAID0 array",
+		},
+		{
+			Name:        string(AssemblyLVM),
+			Value:       AssemblyLVM,
+			Description: "Stripe the devices together with a striped LVM logical volume",
+		},
+	}
+}
+
+func (a Assembly) Check() error {
+	switch a {
+	case AssemblyNone, AssemblyRAID0, AssemblyLVM:
+	default:
+		return fmt.Errorf("unknown disk assembly '%s'", a)
+	}
+
+	return nil
+}
+
+func (a Assembly) PackageNames() []string {
+	switch a {
+	case AssemblyRAID0:
+		return []string{"mdadm"}
+	case AssemblyLVM:
+		return []string{"lvm2"}
+	default:
+		return nil
+	}
+}
+
+type FileSystem string
+
+const (
+	FileSystemExt4 FileSystem = "ext4"
+	FileSystemXFS  FileSystem = "xfs"
+)
+
+func (FileSystem) Values() []infer.EnumValue[FileSystem] {
+	return []infer.EnumValue[FileSystem]{
+		{
+			Name:        string(FileSystemExt4),
+			Value:       FileSystemExt4,
+			Description: "The ext4 filesystem",
+		},
+		{
+			Name:        string(FileSystemXFS),
+			Value:       FileSystemXFS,
+			Description: "The XFS filesystem",
+		},
+	}
+}
+
+func (f FileSystem) Check() error {
+	switch f {
+	case FileSystemExt4, FileSystemXFS:
+	default:
+		return fmt.Errorf("unknown filesystem '%s'", f)
+	}
+
+	return nil
+}
+
+func (f FileSystem) PackageName() string {
+	switch f {
+	case FileSystemXFS:
+		return "xfsprogs"
+	default:
+		return "e2fsprogs"
+	}
+}
+
+var defaultMountOptions = []string{"defaults", "noatime"}
+
+// Volume is a filesystem built from one or more devices and mounted
+// at MountPoint, e.g. the validator's ledger or accounts directory.
+type Volume struct {
+	Name         string      `pulumi:"name"`
+	Devices      []string    `pulumi:"devices"`
+	Assembly     *Assembly   `pulumi:"assembly,optional"`
+	FileSystem   *FileSystem `pulumi:"fileSystem,optional"`
+	MountPoint   string      `pulumi:"mountPoint"`
+	MountOptions *[]string   `pulumi:"mountOptions,optional"`
+
+	// Owner and Group default to the machine's service user.
+	Owner *string `pulumi:"owner,optional"`
+	Group *string `pulumi:"group,optional"`
+	Mode  *string `pulumi:"mode,optional"`
+
+	// Force allows devices that already carry a filesystem, RAID or
+	// LVM signature, or a partition table, to be wiped and
+	// reformatted.  Without it, provisioning stops rather than
+	// destroying data.
+	Force *bool `pulumi:"force,optional"`
+}
+
+func (v *Volume) GetAssembly() Assembly {
+	if v.Assembly == nil {
+		return AssemblyNone
+	}
+
+	return *v.Assembly
+}
+
+func (v *Volume) GetFileSystem() FileSystem {
+	if v.FileSystem == nil {
+		return FileSystemExt4
+	}
+
+	return *v.FileSystem
+}
+
+func (v *Volume) GetMountOptions() []string {
+	if v.MountOptions == nil {
+		return defaultMountOptions
+	}
+
+	return *v.MountOptions
+}
+
+func (v *Volume) Check() error {
+	if v.Name == "" || strings.ContainsAny(v.Name, "/ \t") {
+		return fmt.Errorf("invalid volume name '%s'", v.Name)
+	}
+
+	if err := v.GetAssembly().Check(); err != nil {
+		return err
+	}
+
+	if err := v.GetFileSystem().Check(); err != nil {
+		return err
+	}
+
+	if len(v.Devices) == 0 {
+		return fmt.Errorf("volume '%s' must have at least one device", v.Name)
+	}
+
+	if len(v.Devices) > 1 && v.GetAssembly() == AssemblyNone {
+		return fmt.Errorf("volume '%s' has multiple devices and needs a raid0 or lvm assembly", v.Name)
+	}
+
+	for _, dev := range v.Devices {
+		if !strings.HasPrefix(dev, "/dev/") {
+			return fmt.Errorf("volume '%s' device '%s' is not under /dev", v.Name, dev)
+		}
+	}
+
+	if !path.IsAbs(v.MountPoint) || path.Clean(v.MountPoint) == "/" {
+		return fmt.Errorf("volume '%s' has an invalid mount point '%s'", v.Name, v.MountPoint)
+	}
+
+	for _, opt := range v.GetMountOptions() {
+		if opt == "" || strings.ContainsAny(opt, ", \t") {
+			return fmt.Errorf("volume '%s' has an invalid mount option '%s'", v.Name, opt)
+		}
+	}
+
+	if v.Mode != nil {
+		if m, err := strconv.ParseUint(*v.Mode, 8, 32); err != nil || m > 0o7777 {
+			return fmt.Errorf("volume '%s' has an invalid mode '%s'", v.Name, *v.Mode)
+		}
+	}
+
+	return nil
+}
+
+func (v *Volume) Env() *runner.EnvBuilder {
+	e := runner.NewEnvBuilder()
+
+	e.Set("DISK_NAME", v.Name)
+	e.SetArray("DISK_DEVICES", v.Devices)
+	e.Set("DISK_ASSEMBLY", string(v.GetAssembly()))
+	e.Set("DISK_FS", string(v.GetFileSystem()))
+	e.Set("DISK_MOUNT_POINT", path.Clean(v.MountPoint))
+	e.Set("DISK_MOUNT_OPTIONS", strings.Join(v.GetMountOptions(), ","))
+	e.SetP("DISK_OWNER", v.Owner)
+	e.SetP("DISK_GROUP", v.Group)
+	e.SetP("DISK_MODE", v.Mode)
+	e.SetBool("DISK_FORCE", v.Force != nil && *v.Force)
+
+	return e
+}
+
+type Config struct {
+	Volumes []Volume `pulumi:"volumes"`
+}
+
+func (c *Config) Check() error {
+	var names, mountPoints, devices []string
+
+	for _, v := range c.Volumes {
+		if err := v.Check(); err != nil {
+			return err
+		}
+
+		if slices.Contains(names, v.Name) {
+			return fmt.Errorf("volume '%s' is declared more than once", v.Name)
+		}
+
+		if mp := path.Clean(v.MountPoint); slices.Contains(mountPoints, mp) {
+			return fmt.Errorf("mount point '%s' is used by more than one volume", mp)
+		} else {
+			mountPoints = append(mountPoints, mp)
+		}
+
+		for _, dev := range v.Devices {
+			if slices.Contains(devices, dev) {
+				return fmt.Errorf("device '%s' is used by more than one volume", dev)
+			}
+
+			devices = append(devices, dev)
+		}
+
+		names = append(names, v.Name)
+	}
+
+	return nil
+}
+
+func (c *Config) UpdatePackageGroup(grp *deb.PackageGroup) {
+	for _, v := range c.Volumes {
+		grp.Add(deb.Package{}.MakePackages(v.GetAssembly().PackageNames()...)...)
+		grp.Add(deb.Package{Name: v.GetFileSystem().PackageName()})
+	}
+}
+
+func (c *Config) AddToPayload(p *runner.Payload) error {
+	lib, err := assets.Open(assetsLib)
+
+	if err != nil {
+		return err
+	}
+
+	p.AddReader("disk-lib.sh", lib)
+
+	for _, v := range c.Volumes {
+		p.AddReader(path.Join("disks", v.Name+".env"), v.Env().Buffer())
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package disk
+
+import (
+	"testing"
+
+	"github.com/abklabs/svmkit/pkg/runner/deb"
+	"github.com/stretchr/testify/assert"
+)
+
+func ptr[T any](in T) *T {
+	return &in
+}
+
+func TestVolumeEnv(t *testing.T) {
+	v := Volume{
+		Name:       "ledger",
+		Devices:    []string{"/dev/nvme0n1", "/dev/nvme1n1"},
+		Assembly:   ptr(AssemblyRAID0),
+		MountPoint: "/home/sol/ledger/",
+	}
+
+	assert.Nil(t, v.Check())
+
+	m := v.Env().Map()
+	assert.Equal(t, "ledger", m["DISK_NAME"])
+	assert.Equal(t, "raid0", m["DISK_ASSEMBLY"])
+	assert.Equal(t, "ext4", m["DISK_FS"])
+	assert.Equal(t, "/home/sol/ledger", m["DISK_MOUNT_POINT"])
+	assert.Equal(t, "defaults,noatime", m["DISK_MOUNT_OPTIONS"])
+	assert.Equal(t, "false", m["DISK_FORCE"])
+	assert.NotContains(t, m, "DISK_OWNER")
+}
+
+func TestVolumeCheck(t *testing.T) {
+	valid := func() Volume {
+		return Volume{
+			Name:       "accounts",
+			Devices:    []string{"/dev/nvme0n1"},
+			MountPoint: "/home/sol/accounts",
+		}
+	}
+
+	for name, mutate := range map[string]func(v *Volume){
+		"multiple devices":  func(v *Volume) { v.Devices = append(v.Devices, "/dev/nvme1n1") },
+		"no devices":        func(v *Volume) { v.Devices = nil },
+		"relative device":   func(v *Volume) { v.Devices = []string{"nvme0n1"} },
+		"root mount point":  func(v *Volume) { v.MountPoint = "/" },
+		"relative mount":    func(v *Volume) { v.MountPoint = "accounts" },
+		"bad mount option":  func(v *Volume) { v.MountOptions = &[]string{"noatime,nodiratime"} },
+		"bad mode":          func(v *Volume) { v.Mode = ptr("rwx") },
+		"trailing mode":     func(v *Volume) { v.Mode = ptr("0644abc") },
+		"large mode":        func(v *Volume) { v.Mode = ptr("17777") },
+		"bad filesystem":    func(v *Volume) { v.FileSystem = ptr(FileSystem("zfs")) },
+		"bad assembly":      func(v *Volume) { v.Assembly = ptr(Assembly("raid5")) },
+		"slash in the name": func(v *Volume) { v.Name = "a/b" },
+	} {
+		v := valid()
+		assert.Nil(t, v.Check())
+
+		mutate(&v)
+		assert.Error(t, v.Check(), name)
+	}
+}
+
+func TestConfigCheck(t *testing.T) {
+	c := Config{
+		Volumes: []Volume{
+			{
+				Name:       "ledger",
+				Devices:    []string{"/dev/nvme0n1", "/dev/nvme1n1"},
+				Assembly:   ptr(AssemblyLVM),
+				FileSystem: ptr(FileSystemXFS),
+				MountPoint: "/home/sol/ledger",
+			},
+			{
+				Name:       "accounts",
+				Devices:    []string{"/dev/nvme2n1"},
+				MountPoint: "/home/sol/accounts",
+			},
+		},
+	}
+
+	assert.Nil(t, c.Check())
+
+	grp := deb.Package{}.MakePackageGroup()
+	c.UpdatePackageGroup(grp)
+	assert.Equal(t, []string{"lvm2", "xfsprogs", "e2fsprogs"}, grp.Args())
+
+	c.Volumes[1].Devices = []string{"/dev/nvme1n1"}
+	assert.ErrorContains(t, c.Check(), "device '/dev/nvme1n1'")
+
+	c.Volumes[1].Devices = []string{"/dev/nvme2n1"}
+	c.Volumes[1].MountPoint = "/home/sol/ledger/"
+	assert.ErrorContains(t, c.Check(), "mount point")
+
+	c.Volumes[1].MountPoint = "/home/sol/accounts"
+	c.Volumes[1].Name = "ledger"
+	assert.ErrorContains(t, c.Check(), "more than once")
+}
@@ -0,0 +1,200 @@
+package kernel
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/abklabs/svmkit/pkg/runner"
+	"github.com/pulumi/pulumi-go-provider/infer"
+)
+
+var (
+	cpuListRegexp  = regexp.MustCompile(`^[0-9]+(-[0-9]+)?(,[0-9]+(-[0-9]+)?)*$`)
+	swapSizeRegexp = regexp.MustCompile(`^[1-9][0-9]*[KMG]$`)
+)
+
+type Mitigations string
+
+const (
+	MitigationsAuto      Mitigations = "auto"
+	MitigationsAutoNoSMT Mitigations = "auto,nosmt"
+	MitigationsOff       Mitigations = "off"
+)
+
+func (Mitigations) Values() []infer.EnumValue[Mitigations] {
+	return []infer.EnumValue[Mitigations]{
+		{
+			Name:        "auto",
+			Value:       MitigationsAuto,
+			Description: "Enable the CPU vulnerability mitigations the kernel deems necessary",
+		},
+		{
+			Name:        "autoNoSMT",
+			Value:       MitigationsAutoNoSMT,
+			Description: "As auto, and disable SMT if required to mitigate a vulnerability",
+		},
+		{
+			Name:        "off",
+			Value:       MitigationsOff,
+			Description: "Disable all optional CPU vulnerability mitigations",
+		},
+	}
+}
+
+func (m Mitigations) Check() error {
+	switch m {
+	case MitigationsAuto, MitigationsAutoNoSMT, MitigationsOff:
+	default:
+		return fmt.Errorf("unknown mitigations setting '%s'", m)
+	}
+
+	return nil
+}
+
+// BootParameters are appended to the kernel command line through
+// GRUB.  Changes only take effect after a reboot, which the machine's
+// CreateCommand reports as its machine-reboot-required status.
+type BootParameters struct {
+	// HugePages is the number of 2MiB huge pages to reserve at boot.
+	HugePages *int `pulumi:"hugePages,optional"`
+	// GiganticPages is the number of 1GiB huge pages to reserve at boot.
+	GiganticPages *int `pulumi:"giganticPages,optional"`
+
+	IsolCPUs    *string      `pulumi:"isolCPUs,optional"`
+	NoHzFull    *string      `pulumi:"noHzFull,optional"`
+	Mitigations *Mitigations `pulumi:"mitigations,optional"`
+
+	Extra *[]string `pulumi:"extra,optional"`
+}
+
+func (b *BootParameters) Check() error {
+	if b.HugePages != nil && *b.HugePages < 0 {
+		return fmt.Errorf("hugePages must not be negative")
+	}
+
+	if b.GiganticPages != nil && *b.GiganticPages < 0 {
+		return fmt.Errorf("giganticPages must not be negative")
+	}
+
+	if b.IsolCPUs != nil && !cpuListRegexp.MatchString(*b.IsolCPUs) {
+		return fmt.Errorf("invalid isolCPUs CPU list '%s'", *b.IsolCPUs)
+	}
+
+	if b.NoHzFull != nil && !cpuListRegexp.MatchString(*b.NoHzFull) {
+		return fmt.Errorf("invalid noHzFull CPU list '%s'", *b.NoHzFull)
+	}
+
+	if b.Mitigations != nil {
+		if err := b.Mitigations.Check(); err != nil {
+			return err
+		}
+	}
+
+	if b.Extra != nil {
+		for _, p := range *b.Extra {
+			if p == "" || strings.ContainsAny(p, " \t\n\"'$`\\") {
+				return fmt.Errorf("invalid kernel parameter '%s'", p)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Params returns the kernel command line parameters, in the order
+// they're written to the GRUB configuration.
+func (b *BootParameters) Params() []string {
+	var res []string
+
+	if b.GiganticPages != nil {
+		res = append(res, "hugepagesz=1G", "hugepages="+strconv.Itoa(*b.GiganticPages))
+	}
+
+	if b.HugePages != nil {
+		res = append(res, "hugepagesz=2M", "hugepages="+strconv.Itoa(*b.HugePages))
+	}
+
+	if b.IsolCPUs != nil {
+		res = append(res, "isolcpus="+*b.IsolCPUs)
+	}
+
+	if b.NoHzFull != nil {
+		res = append(res, "nohz_full="+*b.NoHzFull)
+	}
+
+	if b.Mitigations != nil {
+		res = append(res, "mitigations="+string(*b.Mitigations))
+	}
+
+	if b.Extra != nil {
+		res = append(res, *b.Extra...)
+	}
+
+	return res
+}
+
+// Swap is a swap file enabled at boot through /etc/fstab.
+type Swap struct {
+	Path *string `pulumi:"path,optional"`
+	// Size is passed to fallocate, e.g. "16G".
+	Size string `pulumi:"size"`
+}
+
+func (s *Swap) GetPath() string {
+	if s.Path == nil {
+		return "/swapfile"
+	}
+
+	return *s.Path
+}
+
+func (s *Swap) Check() error {
+	if p := s.GetPath(); !path.IsAbs(p) || path.Clean(p) == "/" {
+		return fmt.Errorf("invalid swap file path '%s'", p)
+	}
+
+	if !swapSizeRegexp.MatchString(s.Size) {
+		return fmt.Errorf("invalid swap size '%s'", s.Size)
+	}
+
+	return nil
+}
+
+type Config struct {
+	BootParameters *BootParameters `pulumi:"bootParameters,optional"`
+	Swap           *Swap           `pulumi:"swap,optional"`
+}
+
+func (c *Config) Check() error {
+	if b := c.BootParameters; b != nil {
+		if err := b.Check(); err != nil {
+			return err
+		}
+	}
+
+	if s := c.Swap; s != nil {
+		if err := s.Check(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) Env() *runner.EnvBuilder {
+	e := runner.NewEnvBuilder()
+
+	if b := c.BootParameters; b != nil {
+		e.SetArray("KERNEL_BOOT_PARAMS", b.Params())
+	}
+
+	if s := c.Swap; s != nil {
+		e.Set("SWAP_PATH", path.Clean(s.GetPath()))
+		e.Set("SWAP_SIZE", s.Size)
+	}
+
+	return e
+}
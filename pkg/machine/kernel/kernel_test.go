@@ -0,0 +1,50 @@
+package kernel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ptr[T any](in T) *T {
+	return &in
+}
+
+func TestBootParameters(t *testing.T) {
+	b := BootParameters{
+		HugePages:     ptr(1024),
+		GiganticPages: ptr(4),
+		IsolCPUs:      ptr("2-7,10"),
+		NoHzFull:      ptr("2-7,10"),
+		Mitigations:   ptr(MitigationsOff),
+		Extra:         &[]string{"amd_pstate=active"},
+	}
+
+	assert.Nil(t, b.Check())
+	assert.Equal(t, []string{
+		"hugepagesz=1G", "hugepages=4",
+		"hugepagesz=2M", "hugepages=1024",
+		"isolcpus=2-7,10",
+		"nohz_full=2-7,10",
+		"mitigations=off",
+		"amd_pstate=active",
+	}, b.Params())
+
+	assert.Error(t, (&BootParameters{IsolCPUs: ptr("2-")}).Check())
+	assert.Error(t, (&BootParameters{HugePages: ptr(-1)}).Check())
+	assert.Error(t, (&BootParameters{Mitigations: ptr(Mitigations("sometimes"))}).Check())
+	assert.Error(t, (&BootParameters{Extra: &[]string{"a b"}}).Check())
+}
+
+func TestSwap(t *testing.T) {
+	s := Swap{Size: "16G"}
+	assert.Nil(t, s.Check())
+	assert.Equal(t, "/swapfile", s.GetPath())
+
+	c := Config{Swap: &s}
+	assert.Equal(t, "/swapfile", c.Env().Map()["SWAP_PATH"])
+
+	assert.Error(t, (&Swap{Size: "16GB"}).Check())
+	assert.Error(t, (&Swap{Size: "0G"}).Check())
+	assert.Error(t, (&Swap{Size: "1G", Path: ptr("swapfile")}).Check())
+}
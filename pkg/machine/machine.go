@@ -1,7 +1,16 @@
 package machine
 
 import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/abklabs/svmkit/pkg/logging"
 	"github.com/abklabs/svmkit/pkg/machine/apt"
+	"github.com/abklabs/svmkit/pkg/machine/disk"
+	"github.com/abklabs/svmkit/pkg/machine/kernel"
+	"github.com/abklabs/svmkit/pkg/machine/timesync"
+	"github.com/abklabs/svmkit/pkg/machine/user"
 	"github.com/abklabs/svmkit/pkg/runner"
 	"github.com/abklabs/svmkit/pkg/runner/deb"
 )
@@ -9,7 +18,18 @@ import (
 type Machine struct {
 	runner.RunnerCommand
 
-	AptConfig *apt.Config `pulumi:"aptConfig,optional"`
+	AptConfig   *apt.Config       `pulumi:"aptConfig,optional"`
+	ServiceUser *user.ServiceUser `pulumi:"serviceUser,optional"`
+	Groups      *[]user.Group     `pulumi:"groups,optional"`
+	Users       *[]user.User      `pulumi:"users,optional"`
+	Disks       *disk.Config      `pulumi:"disks,optional"`
+	Kernel      *kernel.Config    `pulumi:"kernel,optional"`
+	TimeSync    *timesync.Config  `pulumi:"timeSync,optional"`
+
+	// Journal sets the journal's size and retention limits, which
+	// are shared by every service on the machine.  Validators log
+	// enough to warrant a few GB.
+	Journal *logging.JournalLimits `pulumi:"journal,optional"`
 }
 
 type CreateCommand struct {
@@ -19,14 +39,112 @@ type CreateCommand struct {
 func (cmd *CreateCommand) Env() *runner.EnvBuilder {
 	tunerEnv := runner.NewEnvBuilder()
 	tunerEnv.Merge(cmd.RunnerCommand.Env())
+
+	if conf := cmd.AptConfig; conf == nil || conf.KeepConflictingLegacySources == nil || !*conf.KeepConflictingLegacySources {
+		tunerEnv.SetArray("APT_CONFLICT_KEYS", cmd.sourceKeys())
+	}
+
+	if s := cmd.ServiceUser; s != nil {
+		tunerEnv.Merge(s.Env())
+		tunerEnv.SetBool("CREATE_SERVICE_USER", true)
+	}
+
+	if k := cmd.Kernel; k != nil {
+		tunerEnv.Merge(k.Env())
+	}
+
+	if t := cmd.TimeSync; t != nil {
+		tunerEnv.Merge(t.Env())
+	}
+
 	return tunerEnv
 }
 
+// sourceKeys returns the "type uri suite" triples that identify the
+// repositories in svmkit.sources, in the same shape the install
+// script extracts from legacy one-line entries.
+func (cmd *CreateCommand) sourceKeys() []string {
+	var keys []string
+
+	for _, s := range cmd.Sources() {
+		uris := make([]string, len(s.URIs))
+
+		for i, v := range s.URIs {
+			uris[i] = strings.TrimSuffix(v, "/")
+		}
+
+		// Only the identifying fields can fail to render, and
+		// we've stripped everything else.
+		lines, _ := apt.Source{Types: s.Types, URIs: uris, Suites: s.Suites}.OneLines()
+		keys = append(keys, lines...)
+	}
+
+	return keys
+}
+
 func (cmd *CreateCommand) Check() error {
 	cmd.SetConfigDefaults()
 
+	if s := cmd.ServiceUser; s != nil {
+		if err := s.Check(); err != nil {
+			return err
+		}
+	}
+
+	if groups := cmd.Groups; groups != nil {
+		for _, g := range *groups {
+			if err := g.Check(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if users := cmd.Users; users != nil {
+		seen := map[string]bool{}
+
+		for _, u := range *users {
+			if err := u.Check(); err != nil {
+				return err
+			}
+
+			if seen[u.Name] {
+				return fmt.Errorf("user '%s' is declared more than once", u.Name)
+			}
+
+			seen[u.Name] = true
+		}
+	}
+
+	if k := cmd.Kernel; k != nil {
+		if err := k.Check(); err != nil {
+			return err
+		}
+	}
+
+	if j := cmd.Journal; j != nil {
+		if err := j.Check(); err != nil {
+			return err
+		}
+	}
+
 	pkgGrp := deb.Package{}.MakePackageGroup()
 
+	if d := cmd.Disks; d != nil {
+		if err := d.Check(); err != nil {
+			return err
+		}
+
+		d.UpdatePackageGroup(pkgGrp)
+	}
+
+	if t := cmd.TimeSync; t != nil {
+		if err := t.Check(); err != nil {
+			return err
+		}
+
+		t.UpdatePackageGroup(pkgGrp)
+	}
+
 	if err := cmd.UpdatePackageGroup(pkgGrp); err != nil {
 		return err
 	}
@@ -34,11 +152,9 @@ func (cmd *CreateCommand) Check() error {
 	return nil
 }
 
-func (cmd *CreateCommand) AddToPayload(p *runner.Payload) error {
-	if err := p.AddTemplate(runner.ScriptNameSteps, installScriptTmpl, cmd); err != nil {
-		return err
-	}
-
+// Sources returns the apt sources this machine should have
+// installed in svmkit.sources.
+func (cmd *CreateCommand) Sources() apt.Sources {
 	sources := apt.Sources{}
 
 	excludeDefaultSources := false
@@ -58,7 +174,7 @@ func (cmd *CreateCommand) AddToPayload(p *runner.Payload) error {
 		sources = append(sources, apt.Source{
 			Types:      []string{"deb"},
 			URIs:       []string{"https://apt.abklabs.com/svmkit"},
-			Suites:     []string{"dev "},
+			Suites:     []string{"dev"},
 			Components: []string{"main"},
 			SignedBy: &apt.SignedBy{
 				PublicKey: &ABKLabsArchiveDevPubKey,
@@ -66,7 +182,15 @@ func (cmd *CreateCommand) AddToPayload(p *runner.Payload) error {
 		})
 	}
 
-	res, err := sources.MarshalText()
+	return sources
+}
+
+func (cmd *CreateCommand) AddToPayload(p *runner.Payload) error {
+	if err := p.AddTemplate(runner.ScriptNameSteps, installScriptTmpl, cmd); err != nil {
+		return err
+	}
+
+	res, err := cmd.Sources().MarshalText()
 
 	if err != nil {
 		return err
@@ -74,6 +198,44 @@ func (cmd *CreateCommand) AddToPayload(p *runner.Payload) error {
 
 	p.NewBuffer(runner.PayloadFile{Path: "svmkit.sources"}, res)
 
+	if groups := cmd.Groups; groups != nil {
+		for _, g := range *groups {
+			p.AddReader(path.Join("groups", g.Name+".env"), g.Env().Buffer())
+		}
+	}
+
+	if users := cmd.Users; users != nil {
+		for _, u := range *users {
+			p.AddReader(path.Join("users", u.Name+".env"), u.Env().Buffer())
+
+			if b := u.AuthorizedKeysFile(); b != nil {
+				p.NewBuffer(runner.PayloadFile{Path: path.Join("users", u.Name+".authorized_keys")}, b)
+			}
+
+			if b := u.SudoersFile(); b != nil {
+				p.NewBuffer(runner.PayloadFile{Path: path.Join("users", u.Name+".sudoers")}, b)
+			}
+		}
+	}
+
+	if t := cmd.TimeSync; t != nil {
+		if b := t.ConfigFile(); b != nil {
+			p.NewBuffer(runner.PayloadFile{Path: "timesync.conf"}, b)
+		}
+	}
+
+	if j := cmd.Journal; j != nil {
+		if b := j.JournaldConf(); b != nil {
+			p.NewBuffer(runner.PayloadFile{Path: "journald.conf"}, b)
+		}
+	}
+
+	if d := cmd.Disks; d != nil {
+		if err := d.AddToPayload(p); err != nil {
+			return err
+		}
+	}
+
 	if err := cmd.RunnerCommand.AddToPayload(p); err != nil {
 		return err
 	}
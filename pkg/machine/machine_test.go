@@ -0,0 +1,99 @@
+package machine
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/abklabs/svmkit/pkg/machine/kernel"
+	"github.com/abklabs/svmkit/pkg/runner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runInstallFunction runs one of the install script's functions with
+// args, with logging and sudo stubbed out.
+func runInstallFunction(t *testing.T, cmd *CreateCommand, args ...string) error {
+	t.Helper()
+
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not available")
+	}
+
+	var script bytes.Buffer
+	require.NoError(t, installScriptTmpl.Execute(&script, cmd))
+
+	path := filepath.Join(t.TempDir(), "install.sh")
+	require.NoError(t, os.WriteFile(path, script.Bytes(), 0644))
+
+	out, err := exec.Command("bash", append([]string{"-c", `
+log::warn() { :; }
+svmkit::sudo() { "$@"; }
+. "$1"
+shift
+"$@"
+`, "bash", path}, args...)...).CombinedOutput()
+
+	if err != nil {
+		t.Log(string(out))
+	}
+
+	return err
+}
+
+func TestDisableConflictingLegacySources(t *testing.T) {
+	cmd := &CreateCommand{}
+
+	list := filepath.Join(t.TempDir(), "sources.list")
+	require.NoError(t, os.WriteFile(list, []byte(`deb http://deb.debian.org/debian bookworm main
+deb [signed-by=/etc/apt/keyrings/abk.gpg] https://apt.abklabs.com/svmkit/ dev main # ours
+deb-src https://apt.abklabs.com/svmkit dev main
+deb https://apt.abklabs.com/svmkit stable main
+`), 0644))
+
+	require.NoError(t, runInstallFunction(t, cmd, append([]string{"disable-conflicting-legacy-sources", list}, cmd.sourceKeys()...)...))
+
+	res, err := os.ReadFile(list)
+	require.NoError(t, err)
+
+	assert.Equal(t, `deb http://deb.debian.org/debian bookworm main
+# disabled by svmkit: deb [signed-by=/etc/apt/keyrings/abk.gpg] https://apt.abklabs.com/svmkit/ dev main # ours
+deb-src https://apt.abklabs.com/svmkit dev main
+deb https://apt.abklabs.com/svmkit stable main
+`, string(res))
+}
+
+func TestBootParamsApplied(t *testing.T) {
+	four := 4
+	b := kernel.BootParameters{HugePages: &four, GiganticPages: &four}
+	cmd := &CreateCommand{Machine: Machine{Kernel: &kernel.Config{BootParameters: &b}}}
+	require.NoError(t, cmd.Check())
+
+	params := cmd.Env().Map()["KERNEL_BOOT_PARAMS"]
+	require.Equal(t, "(hugepagesz=1G hugepages=4 hugepagesz=2M hugepages=4)", params)
+	params = params[1 : len(params)-1]
+
+	applied := func(cmdline string) bool {
+		return runInstallFunction(t, cmd, "boot-params-applied", params, cmdline) == nil
+	}
+
+	assert.True(t, applied("BOOT_IMAGE=/vmlinuz ro hugepagesz=1G hugepages=4 hugepagesz=2M hugepages=4 quiet"))
+	// The right words, but the page counts are attached to the wrong sizes.
+	assert.False(t, applied("ro hugepagesz=1G hugepagesz=2M hugepages=4 hugepages=4"))
+	assert.False(t, applied("ro hugepagesz=1G hugepages=4"))
+}
+
+func TestParseRebootStatus(t *testing.T) {
+	s, err := ParseRebootStatus(&runner.Result{})
+	require.NoError(t, err)
+	assert.Nil(t, s)
+
+	s, err = ParseRebootStatus(&runner.Result{Lines: []string{
+		`svmkit::status machine-reboot-required {"reason":"kernel boot parameters not yet applied: mitigations=off"}`,
+	}})
+	require.NoError(t, err)
+	require.NotNil(t, s)
+	assert.Equal(t, "kernel boot parameters not yet applied: mitigations=off", s.Reason)
+}
@@ -0,0 +1,28 @@
+package machine
+
+import (
+	"github.com/abklabs/svmkit/pkg/runner"
+)
+
+// RebootStatusName is the name CreateCommand reports under when the
+// machine needs a reboot for its configuration to take effect.
+const RebootStatusName = "machine-reboot-required"
+
+// RebootStatus says why the machine needs a reboot.
+type RebootStatus struct {
+	Reason string `json:"reason"`
+}
+
+// ParseRebootStatus returns the reboot CreateCommand reported as
+// required in its result, or nil if the machine needs none.
+func ParseRebootStatus(result *runner.Result) (*RebootStatus, error) {
+	var s RebootStatus
+
+	ok, err := result.Status(RebootStatusName, &s)
+
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	return &s, nil
+}
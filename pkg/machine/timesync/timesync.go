@@ -0,0 +1,150 @@
+package timesync
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/abklabs/svmkit/pkg/runner"
+	"github.com/abklabs/svmkit/pkg/runner/deb"
+	"github.com/pulumi/pulumi-go-provider/infer"
+)
+
+type Daemon string
+
+const (
+	DaemonChrony    Daemon = "chrony"
+	DaemonTimesyncd Daemon = "timesyncd"
+)
+
+func (Daemon) Values() []infer.EnumValue[Daemon] {
+	return []infer.EnumValue[Daemon]{
+		{
+			Name:        string(DaemonChrony),
+			Value:       DaemonChrony,
+			Description: "Synchronize the clock with chrony",
+		},
+		{
+			Name:        string(DaemonTimesyncd),
+			Value:       DaemonTimesyncd,
+			Description: "Synchronize the clock with systemd-timesyncd",
+		},
+	}
+}
+
+func (d Daemon) Check() error {
+	switch d {
+	case DaemonChrony, DaemonTimesyncd:
+	default:
+		return fmt.Errorf("unknown time synchronization daemon '%s'", d)
+	}
+
+	return nil
+}
+
+func (d Daemon) PackageName() string {
+	switch d {
+	case DaemonTimesyncd:
+		return "systemd-timesyncd"
+	default:
+		return "chrony"
+	}
+}
+
+type Config struct {
+	Daemon *Daemon `pulumi:"daemon,optional"`
+
+	// Servers and Pools are added to chrony's default time sources,
+	// while timesyncd uses Servers in place of its defaults.  Pools
+	// are only supported by chrony.
+	Servers *[]string `pulumi:"servers,optional"`
+	Pools   *[]string `pulumi:"pools,optional"`
+
+	// MaxOffset is the largest clock offset from NTP, in seconds,
+	// that the preflight check accepts.
+	MaxOffset *float64 `pulumi:"maxOffset,optional"`
+}
+
+func (c *Config) GetDaemon() Daemon {
+	if c.Daemon == nil {
+		return DaemonChrony
+	}
+
+	return *c.Daemon
+}
+
+func (c *Config) Check() error {
+	if err := c.GetDaemon().Check(); err != nil {
+		return err
+	}
+
+	for _, hosts := range []*[]string{c.Servers, c.Pools} {
+		if hosts == nil {
+			continue
+		}
+
+		for _, h := range *hosts {
+			if h == "" || strings.ContainsAny(h, " \t\r\n#") {
+				return fmt.Errorf("invalid time source '%s'", h)
+			}
+		}
+	}
+
+	if c.Pools != nil && len(*c.Pools) != 0 && c.GetDaemon() != DaemonChrony {
+		return fmt.Errorf("time source pools are only supported by chrony")
+	}
+
+	if c.MaxOffset != nil && *c.MaxOffset <= 0 {
+		return fmt.Errorf("maxOffset must be positive")
+	}
+
+	return nil
+}
+
+func (c *Config) UpdatePackageGroup(grp *deb.PackageGroup) {
+	grp.Add(deb.Package{Name: c.GetDaemon().PackageName()})
+}
+
+func (c *Config) Env() *runner.EnvBuilder {
+	e := runner.NewEnvBuilder()
+
+	e.Set("TIMESYNC_DAEMON", string(c.GetDaemon()))
+	e.SetFloat64P("CLOCK_MAX_OFFSET", c.MaxOffset)
+
+	return e
+}
+
+// ConfigFile renders the daemon's drop-in configuration, or nil if the
+// distribution's default time sources should be used.
+func (c *Config) ConfigFile() []byte {
+	var servers, pools []string
+
+	if c.Servers != nil {
+		servers = *c.Servers
+	}
+
+	if c.Pools != nil {
+		pools = *c.Pools
+	}
+
+	if len(servers) == 0 && len(pools) == 0 {
+		return nil
+	}
+
+	var b strings.Builder
+
+	switch c.GetDaemon() {
+	case DaemonTimesyncd:
+		b.WriteString("[Time]\n")
+		b.WriteString("NTP=" + strings.Join(servers, " ") + "\n")
+	default:
+		for _, s := range servers {
+			b.WriteString("server " + s + " iburst\n")
+		}
+
+		for _, p := range pools {
+			b.WriteString("pool " + p + " iburst\n")
+		}
+	}
+
+	return []byte(b.String())
+}
@@ -0,0 +1,45 @@
+package timesync
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ptr[T any](in T) *T {
+	return &in
+}
+
+func TestConfigFile(t *testing.T) {
+	c := Config{
+		Servers: &[]string{"time1.example.com", "10.0.0.1"},
+		Pools:   &[]string{"pool.ntp.org"},
+	}
+
+	assert.Nil(t, c.Check())
+	assert.Equal(t, "server time1.example.com iburst\nserver 10.0.0.1 iburst\npool pool.ntp.org iburst\n", string(c.ConfigFile()))
+
+	c.Daemon = ptr(DaemonTimesyncd)
+	assert.ErrorContains(t, c.Check(), "only supported by chrony")
+
+	c.Pools = nil
+	assert.Nil(t, c.Check())
+	assert.Equal(t, "[Time]\nNTP=time1.example.com 10.0.0.1\n", string(c.ConfigFile()))
+
+	assert.Nil(t, (&Config{}).ConfigFile())
+}
+
+func TestConfigCheck(t *testing.T) {
+	assert.Error(t, (&Config{Daemon: ptr(Daemon("ntpd"))}).Check())
+	assert.Error(t, (&Config{Servers: &[]string{"a b"}}).Check())
+	assert.Error(t, (&Config{MaxOffset: ptr(0.0)}).Check())
+}
+
+func TestConfigEnv(t *testing.T) {
+	c := Config{MaxOffset: ptr(0.05)}
+
+	assert.Equal(t, map[string]string{
+		"TIMESYNC_DAEMON":  "chrony",
+		"CLOCK_MAX_OFFSET": "0.05",
+	}, c.Env().Map())
+}
@@ -0,0 +1,200 @@
+package user
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/abklabs/svmkit/pkg/runner"
+)
+
+const (
+	DefaultServiceUserName = "sol"
+)
+
+var nameRegexp = regexp.MustCompile(`^[a-z_][a-z0-9_-]{0,31}$`)
+
+func checkName(kind, name string) error {
+	if !nameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid %s name '%s'", kind, name)
+	}
+
+	return nil
+}
+
+// ServiceUser is the account SVMKit services run as, and whose home
+// directory holds their keys, configuration and data.
+type ServiceUser struct {
+	Name  *string `pulumi:"name,optional"`
+	Group *string `pulumi:"group,optional"`
+	Home  *string `pulumi:"home,optional"`
+}
+
+func (s *ServiceUser) GetName() string {
+	if s == nil || s.Name == nil {
+		return DefaultServiceUserName
+	}
+
+	return *s.Name
+}
+
+func (s *ServiceUser) GetGroup() string {
+	if s == nil || s.Group == nil {
+		return s.GetName()
+	}
+
+	return *s.Group
+}
+
+func (s *ServiceUser) GetHome() string {
+	if s == nil || s.Home == nil {
+		return "/home/" + s.GetName()
+	}
+
+	return *s.Home
+}
+
+// Path returns a path relative to the service user's home directory.
+func (s *ServiceUser) Path(elem ...string) string {
+	return path.Join(append([]string{s.GetHome()}, elem...)...)
+}
+
+func (s *ServiceUser) Check() error {
+	if err := checkName("service user", s.GetName()); err != nil {
+		return err
+	}
+
+	if err := checkName("service group", s.GetGroup()); err != nil {
+		return err
+	}
+
+	if home := s.GetHome(); !path.IsAbs(home) || path.Clean(home) == "/" {
+		return fmt.Errorf("invalid service user home directory '%s'", home)
+	}
+
+	return nil
+}
+
+func (s *ServiceUser) Env() *runner.EnvBuilder {
+	e := runner.NewEnvBuilder()
+
+	e.Set("SVMKIT_USER", s.GetName())
+	e.Set("SVMKIT_GROUP", s.GetGroup())
+	e.Set("SVMKIT_HOME", s.GetHome())
+
+	return e
+}
+
+type Group struct {
+	Name string `pulumi:"name"`
+	GID  *int   `pulumi:"gid,optional"`
+}
+
+func (g *Group) Check() error {
+	return checkName("group", g.Name)
+}
+
+func (g *Group) Env() *runner.EnvBuilder {
+	e := runner.NewEnvBuilder()
+
+	e.Set("GROUP_NAME", g.Name)
+	e.SetIntP("GROUP_GID", g.GID)
+
+	return e
+}
+
+// User is an operator account on the machine.
+type User struct {
+	Name   string    `pulumi:"name"`
+	UID    *int      `pulumi:"uid,optional"`
+	Shell  *string   `pulumi:"shell,optional"`
+	Groups *[]string `pulumi:"groups,optional"`
+
+	// AuthorizedKeys replaces the contents of the user's
+	// ~/.ssh/authorized_keys.
+	AuthorizedKeys *[]string `pulumi:"authorizedKeys,optional"`
+
+	// SudoRules are installed in /etc/sudoers.d, each prefixed with
+	// the user's name, e.g. "ALL=(ALL) NOPASSWD:ALL".
+	SudoRules *[]string `pulumi:"sudoRules,optional"`
+}
+
+func (u *User) Check() error {
+	if err := checkName("user", u.Name); err != nil {
+		return err
+	}
+
+	if u.Groups != nil {
+		for _, g := range *u.Groups {
+			if err := checkName("group", g); err != nil {
+				return err
+			}
+		}
+	}
+
+	if u.AuthorizedKeys != nil {
+		for _, k := range *u.AuthorizedKeys {
+			if strings.ContainsAny(k, "\r\n") || len(strings.Fields(k)) < 2 {
+				return fmt.Errorf("invalid authorized key for user '%s'", u.Name)
+			}
+		}
+	}
+
+	if u.SudoRules != nil {
+		for _, r := range *u.SudoRules {
+			if strings.ContainsAny(r, "\r\n") || strings.TrimSpace(r) == "" {
+				return fmt.Errorf("invalid sudo rule for user '%s'", u.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (u *User) Env() *runner.EnvBuilder {
+	e := runner.NewEnvBuilder()
+
+	e.Set("USER_NAME", u.Name)
+	e.SetIntP("USER_UID", u.UID)
+	e.SetP("USER_SHELL", u.Shell)
+	e.SetArrayP("USER_GROUPS", u.Groups)
+
+	return e
+}
+
+// AuthorizedKeysFile renders the user's authorized_keys file, or nil if
+// the user's keys aren't managed.
+func (u *User) AuthorizedKeysFile() []byte {
+	if u.AuthorizedKeys == nil {
+		return nil
+	}
+
+	var b strings.Builder
+
+	for _, k := range *u.AuthorizedKeys {
+		b.WriteString(k)
+		b.WriteString("\n")
+	}
+
+	return []byte(b.String())
+}
+
+// SudoersFile renders the user's sudoers fragment, or nil if the
+// user has no sudo rules.
+func (u *User) SudoersFile() []byte {
+	if u.SudoRules == nil {
+		return nil
+	}
+
+	var b strings.Builder
+
+	for _, r := range *u.SudoRules {
+		b.WriteString(u.Name)
+		b.WriteString(" ")
+		b.WriteString(r)
+		b.WriteString("\n")
+	}
+
+	return []byte(b.String())
+}
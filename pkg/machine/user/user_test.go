@@ -0,0 +1,62 @@
+package user
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ptr[T any](in T) *T {
+	return &in
+}
+
+func TestServiceUserDefaults(t *testing.T) {
+	var s *ServiceUser
+
+	assert.Equal(t, "sol", s.GetName())
+	assert.Equal(t, "sol", s.GetGroup())
+	assert.Equal(t, "/home/sol", s.GetHome())
+	assert.Equal(t, "/home/sol/ledger", s.Path("ledger"))
+	assert.Nil(t, s.Check())
+
+	s = &ServiceUser{Name: ptr("validator")}
+
+	assert.Equal(t, "validator", s.GetGroup())
+	assert.Equal(t, "/home/validator/ledger", s.Path("ledger"))
+	assert.Equal(t, []string{"SVMKIT_USER=validator", "SVMKIT_GROUP=validator", "SVMKIT_HOME=/home/validator"}, s.Env().Args())
+
+	s = &ServiceUser{Name: ptr("validator"), Group: ptr("solana"), Home: ptr("/srv/validator")}
+
+	assert.Equal(t, "/srv/validator/accounts", s.Path("accounts"))
+	assert.Equal(t, []string{"SVMKIT_USER=validator", "SVMKIT_GROUP=solana", "SVMKIT_HOME=/srv/validator"}, s.Env().Args())
+}
+
+func TestServiceUserCheck(t *testing.T) {
+	assert.Error(t, (&ServiceUser{Name: ptr("Bad User")}).Check())
+	assert.Error(t, (&ServiceUser{Group: ptr("0group")}).Check())
+	assert.Error(t, (&ServiceUser{Home: ptr("relative/home")}).Check())
+	assert.Error(t, (&ServiceUser{Home: ptr("/")}).Check())
+}
+
+func TestUser(t *testing.T) {
+	u := User{
+		Name:           "alice",
+		Groups:         &[]string{"sudo", "sol"},
+		AuthorizedKeys: &[]string{"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAI alice@laptop"},
+		SudoRules:      &[]string{"ALL=(ALL) NOPASSWD:ALL"},
+	}
+
+	assert.Nil(t, u.Check())
+	assert.Equal(t, "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAI alice@laptop\n", string(u.AuthorizedKeysFile()))
+	assert.Equal(t, "alice ALL=(ALL) NOPASSWD:ALL\n", string(u.SudoersFile()))
+	assert.Equal(t, []string{"USER_NAME=alice", "USER_GROUPS=(sudo sol)"}, u.Env().Args())
+
+	assert.Nil(t, (&User{Name: "bob"}).AuthorizedKeysFile())
+	assert.Nil(t, (&User{Name: "bob"}).SudoersFile())
+
+	assert.Error(t, (&User{Name: "root!"}).Check())
+	assert.Error(t, (&User{Name: "bob", Groups: &[]string{"Bad"}}).Check())
+	assert.Error(t, (&User{Name: "bob", AuthorizedKeys: &[]string{"ssh-ed25519"}}).Check())
+	assert.Error(t, (&User{Name: "bob", AuthorizedKeys: &[]string{"ssh-ed25519 AAAA\nssh-rsa BBBB"}}).Check())
+	assert.Error(t, (&User{Name: "bob", SudoRules: &[]string{"ALL=(ALL) ALL\nroot ALL=(ALL) ALL"}}).Check())
+}
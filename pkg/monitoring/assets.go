@@ -0,0 +1,13 @@
+package monitoring
+
+import (
+	"embed"
+)
+
+//go:embed assets
+var assets embed.FS
+
+const (
+	assetsInstallScript   = "assets/install.sh"
+	assetsUninstallScript = "assets/uninstall.sh"
+)
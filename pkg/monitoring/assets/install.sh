@@ -0,0 +1,25 @@
+# -*- mode: shell-script -*-
+# shellcheck shell=bash
+
+PROMETHEUS_SERVICE=prometheus.service
+
+step::00::wait-for-a-stable-environment() {
+    cloud-init::wait-for-stable-environment
+}
+
+step::10::install-packages() {
+    svmkit::apt::get install "${PACKAGE_LIST[@]}"
+}
+
+step::20::configure-prometheus() {
+    promtool check config prometheus.yml
+
+    # The configuration may hold remote write credentials.
+    svmkit::sudo install -m 640 -o root -g prometheus prometheus.yml /etc/prometheus/prometheus.yml
+    svmkit::sudo install -m 644 -o root -g root prometheus.default /etc/default/prometheus
+}
+
+step::30::restart-prometheus() {
+    svmkit::sudo systemctl enable "${PROMETHEUS_SERVICE}"
+    svmkit::sudo systemctl restart "${PROMETHEUS_SERVICE}"
+}
@@ -0,0 +1,12 @@
+# -*- mode: shell-script -*-
+# shellcheck shell=bash
+
+PROMETHEUS_SERVICE=prometheus.service
+
+step::00::wait-for-a-stable-environment() {
+    cloud-init::wait-for-stable-environment
+}
+
+step::10::stop-prometheus() {
+    svmkit::sudo systemctl disable --now "${PROMETHEUS_SERVICE}" || true
+}
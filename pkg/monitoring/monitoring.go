@@ -0,0 +1,341 @@
+package monitoring
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/abklabs/svmkit/pkg/runner"
+	"github.com/abklabs/svmkit/pkg/runner/deb"
+)
+
+const (
+	defaultScrapeInterval = "15s"
+	defaultListenAddress  = "127.0.0.1:9090"
+	defaultRetention      = "15d"
+)
+
+var (
+	durationRegexp = regexp.MustCompile(`^([0-9]+(ms|s|m|h|d|w|y))+$`)
+	jobRegexp      = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+	labelRegexp    = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// Target is a scrape job: a set of host:port endpoints serving
+// Prometheus metrics at MetricsPath, which defaults to /metrics.
+type Target struct {
+	Job         string            `pulumi:"job"`
+	Targets     []string          `pulumi:"targets"`
+	MetricsPath *string           `pulumi:"metricsPath,optional"`
+	Labels      map[string]string `pulumi:"labels,optional"`
+}
+
+func (t *Target) Check() error {
+	if !jobRegexp.MatchString(t.Job) {
+		return fmt.Errorf("invalid scrape job name '%s'", t.Job)
+	}
+
+	if len(t.Targets) == 0 {
+		return fmt.Errorf("scrape job '%s' has no targets", t.Job)
+	}
+
+	for _, target := range t.Targets {
+		if err := checkHostPort(target); err != nil {
+			return fmt.Errorf("scrape job '%s': %w", t.Job, err)
+		}
+	}
+
+	if p := t.MetricsPath; p != nil && !strings.HasPrefix(*p, "/") {
+		return fmt.Errorf("scrape job '%s' has an invalid metrics path '%s'", t.Job, *p)
+	}
+
+	for name := range t.Labels {
+		if !labelRegexp.MatchString(name) || strings.HasPrefix(name, "__") {
+			return fmt.Errorf("scrape job '%s' has an invalid label name '%s'", t.Job, name)
+		}
+	}
+
+	return nil
+}
+
+// RemoteWrite forwards scraped samples to another Prometheus, or any
+// service accepting its remote write protocol.
+type RemoteWrite struct {
+	URL      string  `pulumi:"url"`
+	Username *string `pulumi:"username,optional"`
+	Password *string `pulumi:"password,optional" provider:"secret"`
+}
+
+func (r *RemoteWrite) Check() error {
+	parsed, err := url.ParseRequestURI(r.URL)
+
+	if err != nil {
+		return fmt.Errorf("invalid remote write URL '%s': %w", r.URL, err)
+	}
+
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("remote write URL '%s' must be http or https", r.URL)
+	}
+
+	if (r.Username == nil) != (r.Password == nil) {
+		return fmt.Errorf("remote write needs both a username and password, or neither")
+	}
+
+	return nil
+}
+
+// Monitoring runs a Prometheus server that scrapes Targets, such as
+// the one firedancer.Firedancer's MetricsTarget returns.  It listens
+// on ListenAddress, only to the host itself by default, and keeps
+// samples for Retention.
+type Monitoring struct {
+	runner.RunnerCommand
+
+	Targets        []Target     `pulumi:"targets"`
+	ScrapeInterval *string      `pulumi:"scrapeInterval,optional"`
+	ListenAddress  *string      `pulumi:"listenAddress,optional"`
+	Retention      *string      `pulumi:"retention,optional"`
+	RemoteWrite    *RemoteWrite `pulumi:"remoteWrite,optional"`
+}
+
+func (m *Monitoring) Install() runner.Command {
+	return &InstallCommand{
+		Monitoring: *m,
+	}
+}
+
+func (m *Monitoring) Uninstall() runner.Command {
+	return &UninstallCommand{
+		Monitoring: *m,
+	}
+}
+
+func (m *Monitoring) check() error {
+	m.SetConfigDefaults()
+
+	jobs := map[string]bool{}
+
+	for i := range m.Targets {
+		t := &m.Targets[i]
+
+		if err := t.Check(); err != nil {
+			return err
+		}
+
+		if jobs[t.Job] {
+			return fmt.Errorf("scrape job '%s' is listed more than once", t.Job)
+		}
+
+		jobs[t.Job] = true
+	}
+
+	if s := m.ScrapeInterval; s != nil && !durationRegexp.MatchString(*s) {
+		return fmt.Errorf("invalid scrape interval '%s'", *s)
+	}
+
+	if r := m.Retention; r != nil && !durationRegexp.MatchString(*r) {
+		return fmt.Errorf("invalid retention '%s'", *r)
+	}
+
+	if a := m.ListenAddress; a != nil {
+		if err := checkHostPort(*a); err != nil {
+			return err
+		}
+
+		if host, _, _ := net.SplitHostPort(*a); net.ParseIP(host) == nil {
+			return fmt.Errorf("the listen address must be an IP address, not '%s'", host)
+		}
+	}
+
+	if r := m.RemoteWrite; r != nil {
+		if err := r.Check(); err != nil {
+			return err
+		}
+	}
+
+	grp := deb.Package{}.MakePackageGroup("prometheus")
+
+	return m.UpdatePackageGroup(grp)
+}
+
+type scrapeConfig struct {
+	JobName       string         `yaml:"job_name"`
+	MetricsPath   string         `yaml:"metrics_path,omitempty"`
+	StaticConfigs []staticConfig `yaml:"static_configs"`
+}
+
+type staticConfig struct {
+	Targets []string          `yaml:"targets"`
+	Labels  map[string]string `yaml:"labels,omitempty"`
+}
+
+type remoteWriteConfig struct {
+	URL       string     `yaml:"url"`
+	BasicAuth *basicAuth `yaml:"basic_auth,omitempty"`
+}
+
+type basicAuth struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+type prometheusConfig struct {
+	Global struct {
+		ScrapeInterval string `yaml:"scrape_interval"`
+	} `yaml:"global"`
+	ScrapeConfigs []scrapeConfig      `yaml:"scrape_configs"`
+	RemoteWrite   []remoteWriteConfig `yaml:"remote_write,omitempty"`
+}
+
+// config renders prometheus.yml.
+func (m *Monitoring) config() ([]byte, error) {
+	var c prometheusConfig
+
+	c.Global.ScrapeInterval = defaultScrapeInterval
+	if s := m.ScrapeInterval; s != nil {
+		c.Global.ScrapeInterval = *s
+	}
+
+	c.ScrapeConfigs = []scrapeConfig{}
+
+	for _, t := range m.Targets {
+		sc := scrapeConfig{
+			JobName:       t.Job,
+			StaticConfigs: []staticConfig{{Targets: t.Targets, Labels: t.Labels}},
+		}
+
+		if p := t.MetricsPath; p != nil {
+			sc.MetricsPath = *p
+		}
+
+		c.ScrapeConfigs = append(c.ScrapeConfigs, sc)
+	}
+
+	if r := m.RemoteWrite; r != nil {
+		rw := remoteWriteConfig{URL: r.URL}
+
+		if r.Username != nil && r.Password != nil {
+			rw.BasicAuth = &basicAuth{Username: *r.Username, Password: *r.Password}
+		}
+
+		c.RemoteWrite = append(c.RemoteWrite, rw)
+	}
+
+	var buf bytes.Buffer
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(&c); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// args returns the flags the prometheus service is started with.
+func (m *Monitoring) args() []string {
+	listen := defaultListenAddress
+	if a := m.ListenAddress; a != nil {
+		listen = *a
+	}
+
+	retention := defaultRetention
+	if r := m.Retention; r != nil {
+		retention = *r
+	}
+
+	return []string{
+		"--web.listen-address=" + listen,
+		"--storage.tsdb.retention.time=" + retention,
+	}
+}
+
+func checkHostPort(hostPort string) error {
+	host, port, err := net.SplitHostPort(hostPort)
+
+	if err != nil {
+		return fmt.Errorf("invalid address '%s': %w", hostPort, err)
+	}
+
+	if host == "" {
+		return fmt.Errorf("address '%s' has no host", hostPort)
+	}
+
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("invalid port in address '%s'", hostPort)
+	}
+
+	return nil
+}
+
+// InstallCommand installs Prometheus from the distribution and
+// configures it to scrape the targets.
+type InstallCommand struct {
+	Monitoring
+}
+
+func (c *InstallCommand) Check() error {
+	return c.check()
+}
+
+func (c *InstallCommand) Env() *runner.EnvBuilder {
+	return c.RunnerCommand.Env()
+}
+
+func (c *InstallCommand) AddToPayload(p *runner.Payload) error {
+	steps, err := assets.Open(assetsInstallScript)
+
+	if err != nil {
+		return err
+	}
+
+	p.AddReader(runner.ScriptNameSteps, steps)
+
+	config, err := c.config()
+
+	if err != nil {
+		return err
+	}
+
+	p.AddString("prometheus.yml", string(config))
+	p.AddString("prometheus.default", fmt.Sprintf("ARGS=\"%s\"\n", strings.Join(c.args(), " ")))
+
+	return c.RunnerCommand.AddToPayload(p)
+}
+
+// UninstallCommand stops and disables Prometheus.  The package and
+// the samples it collected are left in place.
+type UninstallCommand struct {
+	Monitoring
+}
+
+func (c *UninstallCommand) Check() error {
+	return c.check()
+}
+
+func (c *UninstallCommand) Env() *runner.EnvBuilder {
+	return c.RunnerCommand.Env()
+}
+
+func (c *UninstallCommand) AddToPayload(p *runner.Payload) error {
+	steps, err := assets.Open(assetsUninstallScript)
+
+	if err != nil {
+		return err
+	}
+
+	p.AddReader(runner.ScriptNameSteps, steps)
+
+	return c.RunnerCommand.AddToPayload(p)
+}
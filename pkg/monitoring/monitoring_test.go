@@ -0,0 +1,125 @@
+package monitoring
+
+import (
+	"testing"
+
+	"github.com/abklabs/svmkit/pkg/runner"
+	"github.com/abklabs/svmkit/pkg/runner/runnertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ptr[T any](in T) *T {
+	return &in
+}
+
+func TestTargetCheck(t *testing.T) {
+	target := Target{Job: "svmkit-fd-validator", Targets: []string{"127.0.0.1:7999"}}
+	assert.NoError(t, target.Check())
+
+	target.MetricsPath = ptr("metrics")
+	assert.Error(t, target.Check())
+
+	target.MetricsPath = ptr("/metrics")
+	target.Labels = map[string]string{"__name__": "x"}
+	assert.Error(t, target.Check())
+
+	target.Labels = map[string]string{"cluster": "testnet"}
+	assert.NoError(t, target.Check())
+
+	for _, targets := range [][]string{nil, {"127.0.0.1"}, {":7999"}, {"127.0.0.1:0"}} {
+		assert.Error(t, (&Target{Job: "validator", Targets: targets}).Check())
+	}
+
+	assert.Error(t, (&Target{Job: "bad job", Targets: []string{"127.0.0.1:7999"}}).Check())
+}
+
+func TestRemoteWriteCheck(t *testing.T) {
+	r := RemoteWrite{URL: "https://metrics.example.com/api/v1/write"}
+	assert.NoError(t, r.Check())
+
+	r.Username = ptr("svmkit")
+	assert.Error(t, r.Check())
+
+	r.Password = ptr("secret")
+	assert.NoError(t, r.Check())
+
+	r.URL = "metrics.example.com"
+	assert.Error(t, r.Check())
+}
+
+func TestMonitoringCheck(t *testing.T) {
+	target := Target{Job: "svmkit-fd-validator", Targets: []string{"127.0.0.1:7999"}}
+
+	m := Monitoring{}
+	assert.NoError(t, m.Install().Check())
+
+	m.Targets = []Target{target}
+	m.ScrapeInterval = ptr("1m30s")
+	m.Retention = ptr("30d")
+	m.ListenAddress = ptr("0.0.0.0:9090")
+	assert.NoError(t, m.Install().Check())
+
+	m.Targets = []Target{target, target}
+	assert.Error(t, m.Install().Check())
+	m.Targets = []Target{target}
+
+	m.ScrapeInterval = ptr("15")
+	assert.Error(t, m.Install().Check())
+	m.ScrapeInterval = nil
+
+	m.Retention = ptr("a month")
+	assert.Error(t, m.Install().Check())
+	m.Retention = nil
+
+	m.ListenAddress = ptr("localhost:9090")
+	assert.Error(t, m.Install().Check())
+	m.ListenAddress = nil
+
+	m.RemoteWrite = &RemoteWrite{URL: "https://metrics.example.com", Username: ptr("svmkit")}
+	assert.Error(t, m.Install().Check())
+}
+
+func TestMonitoringPayload(t *testing.T) {
+	m := Monitoring{
+		Targets: []Target{
+			{Job: "svmkit-fd-validator", Targets: []string{"127.0.0.1:7999"}, Labels: map[string]string{"cluster": "testnet"}},
+			{Job: "node", Targets: []string{"127.0.0.1:9100"}, MetricsPath: ptr("/metrics")},
+		},
+		Retention:   ptr("30d"),
+		RemoteWrite: &RemoteWrite{URL: "https://metrics.example.com/api/v1/write", Username: ptr("svmkit"), Password: ptr("secret")},
+	}
+
+	cmd := m.Install()
+	require.NoError(t, cmd.Check())
+
+	files := runnertest.PayloadFiles(t, cmd)
+
+	assert.Equal(t, `global:
+  scrape_interval: 15s
+scrape_configs:
+  - job_name: svmkit-fd-validator
+    static_configs:
+      - targets:
+          - 127.0.0.1:7999
+        labels:
+          cluster: testnet
+  - job_name: node
+    metrics_path: /metrics
+    static_configs:
+      - targets:
+          - 127.0.0.1:9100
+remote_write:
+  - url: https://metrics.example.com/api/v1/write
+    basic_auth:
+      username: svmkit
+      password: secret
+`, files["prometheus.yml"])
+
+	assert.Equal(t, "ARGS=\"--web.listen-address=127.0.0.1:9090 --storage.tsdb.retention.time=30d\"\n", files["prometheus.default"])
+	assert.Contains(t, files, runner.ScriptNameSteps)
+
+	uninstall := m.Uninstall()
+	require.NoError(t, uninstall.Check())
+	assert.NotContains(t, runnertest.PayloadFiles(t, uninstall), "prometheus.yml")
+}
@@ -4,9 +4,11 @@ import (
 	"github.com/abklabs/svmkit/pkg/runner"
 
 	"github.com/abklabs/svmkit/pkg/agave"
+	"github.com/abklabs/svmkit/pkg/backup"
 	"github.com/abklabs/svmkit/pkg/firedancer"
 	"github.com/abklabs/svmkit/pkg/firewall"
 	"github.com/abklabs/svmkit/pkg/machine"
+	"github.com/abklabs/svmkit/pkg/monitoring"
 	"github.com/abklabs/svmkit/pkg/solana"
 	"github.com/abklabs/svmkit/pkg/solana/explorer"
 	"github.com/abklabs/svmkit/pkg/solana/faucet"
@@ -30,6 +32,9 @@ const (
 	ComponentFirewall
 	ComponentVoteAccount
 	ComponentWatchtower
+	ComponentBackup
+	ComponentBackupRestore
+	ComponentMonitoring
 )
 
 func (a Component) String() string {
@@ -58,12 +63,21 @@ func (a Component) String() string {
 		return "voteAccount"
 	case ComponentWatchtower:
 		return "watchtower"
+	case ComponentBackup:
+		return "backup"
+	case ComponentBackupRestore:
+		return "backupRestore"
+	case ComponentMonitoring:
+		return "monitoring"
 
 	default:
 		return "invalid"
 	}
 }
 
+// ComponentOp maps an action on a component to the command that
+// performs it.  Only components with an update command of their own,
+// which applies changes in place, register ActionUpdate.
 type ComponentOp struct {
 	Action  Action
 	Creator func() runner.Command
@@ -86,6 +100,18 @@ var Components = []ComponentCommand{
 					return &agave.InstallCommand{}
 				},
 			},
+			{
+				ActionUpdate,
+				func() runner.Command {
+					return &agave.UpdateCommand{}
+				},
+			},
+			{
+				ActionDelete,
+				func() runner.Command {
+					return &agave.UninstallCommand{}
+				},
+			},
 		},
 	},
 	{
@@ -98,6 +124,12 @@ var Components = []ComponentCommand{
 					return &firedancer.InstallCommand{}
 				},
 			},
+			{
+				ActionUpdate,
+				func() runner.Command {
+					return &firedancer.UpdateCommand{}
+				},
+			},
 			{
 				ActionDelete,
 				func() runner.Command {
@@ -140,6 +172,12 @@ var Components = []ComponentCommand{
 					return &genesis.CreateCommand{}
 				},
 			},
+			{
+				ActionDelete,
+				func() runner.Command {
+					return &genesis.DeleteCommand{}
+				},
+			},
 		},
 	},
 	{
@@ -173,7 +211,7 @@ var Components = []ComponentCommand{
 			{
 				ActionCreate,
 				func() runner.Command {
-					return &solana.StakeAccountCreate{}
+					return &solana.TransferCreate{}
 				},
 			},
 		},
@@ -232,4 +270,52 @@ var Components = []ComponentCommand{
 			},
 		},
 	},
+	{
+		ComponentBackup,
+		"Periodically back up a validator's snapshots and tower.",
+		[]*ComponentOp{
+			{
+				ActionCreate,
+				func() runner.Command {
+					return &backup.InstallCommand{}
+				},
+			},
+			{
+				ActionDelete,
+				func() runner.Command {
+					return &backup.UninstallCommand{}
+				},
+			},
+		},
+	},
+	{
+		ComponentBackupRestore,
+		"Restore a validator's snapshots and tower from a backup.",
+		[]*ComponentOp{
+			{
+				ActionCreate,
+				func() runner.Command {
+					return &backup.RestoreCommand{}
+				},
+			},
+		},
+	},
+	{
+		ComponentMonitoring,
+		"Scrape metrics, such as a validator's, with Prometheus.",
+		[]*ComponentOp{
+			{
+				ActionCreate,
+				func() runner.Command {
+					return &monitoring.InstallCommand{}
+				},
+			},
+			{
+				ActionDelete,
+				func() runner.Command {
+					return &monitoring.UninstallCommand{}
+				},
+			},
+		},
+	},
 }
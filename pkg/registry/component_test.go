@@ -0,0 +1,104 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/abklabs/svmkit/pkg/agave"
+	"github.com/abklabs/svmkit/pkg/backup"
+	"github.com/abklabs/svmkit/pkg/firedancer"
+	"github.com/abklabs/svmkit/pkg/firewall"
+	"github.com/abklabs/svmkit/pkg/machine"
+	"github.com/abklabs/svmkit/pkg/monitoring"
+	"github.com/abklabs/svmkit/pkg/runner"
+	"github.com/abklabs/svmkit/pkg/solana"
+	"github.com/abklabs/svmkit/pkg/solana/explorer"
+	"github.com/abklabs/svmkit/pkg/solana/faucet"
+	"github.com/abklabs/svmkit/pkg/solana/genesis"
+	"github.com/abklabs/svmkit/pkg/solana/watchtower"
+	"github.com/abklabs/svmkit/pkg/tuner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponentOps(t *testing.T) {
+	expected := map[Component]map[Action]runner.Command{
+		ComponentAgave: {
+			ActionCreate: &agave.InstallCommand{},
+			ActionUpdate: &agave.UpdateCommand{},
+			ActionDelete: &agave.UninstallCommand{},
+		},
+		ComponentFiredancer: {
+			ActionCreate: &firedancer.InstallCommand{},
+			ActionUpdate: &firedancer.UpdateCommand{},
+			ActionDelete: &firedancer.UninstallCommand{},
+		},
+		ComponentExplorer: {
+			ActionCreate: &explorer.ExplorerCommand{},
+		},
+		ComponentFaucet: {
+			ActionCreate: &faucet.InstallCommand{},
+		},
+		ComponentGenesis: {
+			ActionCreate: &genesis.CreateCommand{},
+			ActionDelete: &genesis.DeleteCommand{},
+		},
+		ComponentMachine: {
+			ActionCreate: &machine.CreateCommand{},
+		},
+		ComponentStakeAccount: {
+			ActionCreate: &solana.StakeAccountCreate{},
+		},
+		ComponentTransfer: {
+			ActionCreate: &solana.TransferCreate{},
+		},
+		ComponentTuner: {
+			ActionCreate: &tuner.TunerCommand{},
+		},
+		ComponentFirewall: {
+			ActionCreate: &firewall.FirewallCommand{},
+		},
+		ComponentWatchtower: {
+			ActionCreate: &watchtower.InstallCommand{},
+		},
+		ComponentVoteAccount: {
+			ActionCreate: &solana.VoteAccountCreate{},
+			ActionDelete: &solana.VoteAccountDelete{},
+		},
+		ComponentBackup: {
+			ActionCreate: &backup.InstallCommand{},
+			ActionDelete: &backup.UninstallCommand{},
+		},
+		ComponentBackupRestore: {
+			ActionCreate: &backup.RestoreCommand{},
+		},
+		ComponentMonitoring: {
+			ActionCreate: &monitoring.InstallCommand{},
+			ActionDelete: &monitoring.UninstallCommand{},
+		},
+	}
+
+	require.Len(t, Components, len(expected))
+
+	for _, comp := range Components {
+		ops, ok := expected[comp.Name]
+		require.True(t, ok, "unexpected component %s", comp.Name)
+
+		seen := map[Action]bool{}
+
+		for i, op := range comp.Op {
+			assert.False(t, seen[op.Action], "%s registers %s more than once", comp.Name, op.Action)
+			seen[op.Action] = true
+
+			if i > 0 {
+				assert.Less(t, comp.Op[i-1].Action, op.Action, "%s ops are out of order", comp.Name)
+			}
+
+			want, ok := ops[op.Action]
+			if assert.True(t, ok, "unexpected %s op for %s", op.Action, comp.Name) {
+				assert.IsType(t, want, op.Creator(), "%s %s", comp.Name, op.Action)
+			}
+		}
+
+		assert.Len(t, seen, len(ops), "%s is missing ops", comp.Name)
+	}
+}
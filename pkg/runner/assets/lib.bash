@@ -108,25 +108,93 @@ cloud-init::wait-for-stable-environment() {
     fi
 }
 
+# Install SRC at DST with the given owner ("user:group") and mode,
+# unless DST already has the same contents.  Returns non-zero if DST
+# was left alone, so callers can tell whether anything changed.
+svmkit::install-file() {
+    local src=$1 dst=$2 owner=$3 mode=$4
+
+    svmkit::sudo cmp -s "$src" "$dst" && return 1
+
+    svmkit::sudo install -m "$mode" -o "${owner%%:*}" -g "${owner#*:}" "$src" "$dst" || log::fatal "failed to install '$dst'"
+}
+
+# Report a status called NAME to the caller.  JSON must be a single
+# line; runner.ParseStatus picks it out of the command's output.
+svmkit::status() {
+    local name=$1 json=$2
+
+    printf 'svmkit::status %s %s\n' "$name" "$json"
+}
+
+# The service user SVMKit components run as.  Components override
+# these through their environment.
+: "${SVMKIT_USER:=sol}"
+: "${SVMKIT_GROUP:=$SVMKIT_USER}"
+: "${SVMKIT_HOME:=/home/$SVMKIT_USER}"
+
 create-sol-user() {
     local username
 
     svmkit::flock::start
-    id sol >/dev/null 2>&1 || svmkit::sudo adduser --disabled-password --gecos "" sol
-    svmkit::sudo mkdir -p "/home/sol"
-    svmkit::sudo chown -f -R sol:sol "/home/sol"
-    svmkit::sudo chmod 750 "/home/sol"
+    getent group "$SVMKIT_GROUP" >/dev/null 2>&1 || svmkit::sudo addgroup "$SVMKIT_GROUP"
+    id "$SVMKIT_USER" >/dev/null 2>&1 || svmkit::sudo adduser --disabled-password --gecos "" --home "$SVMKIT_HOME" --ingroup "$SVMKIT_GROUP" "$SVMKIT_USER"
+    svmkit::sudo mkdir -p "$SVMKIT_HOME"
+    svmkit::sudo chown -f -R "$SVMKIT_USER:$SVMKIT_GROUP" "$SVMKIT_HOME"
+    svmkit::sudo chmod 750 "$SVMKIT_HOME"
 
     username=$(whoami)
-    id -nGz "$username" | grep -qzxF sol || svmkit::sudo adduser "$username" sol
+    id -nGz "$username" | grep -qzxF "$SVMKIT_GROUP" || svmkit::sudo adduser "$username" "$SVMKIT_GROUP"
 
-    cat <<EOF | svmkit::sudo tee /etc/security/limits.d/50-sol.conf >/dev/null
-sol    soft    nofile    1000000
-sol    hard    nofile    1000000
+    cat <<EOF | svmkit::sudo tee "/etc/security/limits.d/50-$SVMKIT_USER.conf" >/dev/null
+$SVMKIT_USER    soft    nofile    1000000
+$SVMKIT_USER    hard    nofile    1000000
 EOF
 
-    svmkit::sudo chown root:root /etc/security/limits.d/50-sol.conf
-    svmkit::sudo chmod 644 /etc/security/limits.d/50-sol.conf
+    svmkit::sudo chown root:root "/etc/security/limits.d/50-$SVMKIT_USER.conf"
+    svmkit::sudo chmod 644 "/etc/security/limits.d/50-$SVMKIT_USER.conf"
 
     svmkit::flock::end
 }
+
+# Report the clock's offset from its NTP sources before starting a
+# service that depends on accurate time.  If CLOCK_MAX_OFFSET is set,
+# an offset larger than that many seconds is fatal.
+svmkit::clock::preflight() {
+    local tracking="" offset="" synced=""
+
+    if command -v chronyc >/dev/null 2>&1; then
+        tracking=$(chronyc -c tracking 2>/dev/null || true)
+    fi
+
+    if [[ -n "$tracking" ]]; then
+        # Field 5 is the system time offset; the last is the leap status.
+        offset=$(cut -d, -f5 <<<"$tracking")
+        synced=no
+        [[ "${tracking##*,}" = "Normal" ]] && synced=yes
+    elif command -v timedatectl >/dev/null 2>&1; then
+        synced=$(timedatectl show -p NTPSynchronized --value 2>/dev/null || true)
+        offset=$(timedatectl timesync-status 2>/dev/null | awk '
+$1 == "Offset:" {
+    v = $2; m = 1
+    if (v ~ /(us|µs|μs)$/) m = 0.000001; else if (v ~ /ms$/) m = 0.001
+    sub(/[^0-9.]+$/, "", v)
+    printf "%.9f\n", v * m
+}')
+    fi
+
+    if [[ -z "$offset" ]]; then
+        log::warn "preflight: unable to determine the clock offset; is time synchronization running?"
+        return 0
+    fi
+
+    log::info "preflight: clock offset from NTP is ${offset}s (synchronized: ${synced:-unknown})"
+
+    [[ "$synced" = "yes" ]] || log::warn "preflight: the clock is not synchronized"
+
+    [[ -v CLOCK_MAX_OFFSET ]] || return 0
+
+    if awk -v o="$offset" -v m="$CLOCK_MAX_OFFSET" 'BEGIN { exit !((o < 0 ? -o : o) > m) }'; then
+        log::fatal "preflight: clock offset of ${offset}s exceeds the maximum of ${CLOCK_MAX_OFFSET}s"
+    fi
+}
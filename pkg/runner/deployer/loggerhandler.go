@@ -54,6 +54,11 @@ func (h *LoggerHandler) IngestReaders(done chan<- struct{}, stdout io.Reader, st
 	return nil
 }
 
+// Lines returns the command's output, once it has been drained.
+func (h *LoggerHandler) Lines() []string {
+	return h.lines
+}
+
 func (h *LoggerHandler) AugmentError(err error) error {
 	return fmt.Errorf("\n%s\n%w", strings.Join(h.lines, "\n"), err)
 }
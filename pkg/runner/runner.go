@@ -19,6 +19,12 @@ type Command interface {
 	Config() *Config
 }
 
+// CommandWithWarnings is implemented by commands whose Check can find
+// problems worth reporting that don't stop the command from running.
+type CommandWithWarnings interface {
+	Warnings() []string
+}
+
 func NewRunner(client *ssh.Client, cmd Command) *Runner {
 	return &Runner{client: client, command: cmd}
 }
@@ -42,13 +48,21 @@ func PrepareCommandPayload(p *Payload, command Command) error {
 }
 
 func (r *Runner) Run(ctx context.Context, handler deployer.DeployerHandler, statusCallback deployer.ProgressStatusCallback) error {
+	_, err := r.RunWithResult(ctx, handler, statusCallback)
+	return err
+}
+
+// RunWithResult is Run, also returning the status reports the command
+// made.  The Result is returned even if the command failed once it had
+// started.
+func (r *Runner) RunWithResult(ctx context.Context, handler deployer.DeployerHandler, statusCallback deployer.ProgressStatusCallback) (*Result, error) {
 	p := &Payload{
 		RootPath:    fmt.Sprintf("/tmp/runner-%d-%d", time.Now().Unix(), rand.Int()),
 		DefaultMode: 0640,
 	}
 
 	if err := PrepareCommandPayload(p, r.command); err != nil {
-		return err
+		return nil, err
 	}
 
 	keepPayload := false
@@ -61,12 +75,14 @@ func (r *Runner) Run(ctx context.Context, handler deployer.DeployerHandler, stat
 
 	d := deployer.SSH{Payload: p, Client: r.client, KeepPayload: keepPayload}
 	if err := d.Deploy(statusCallback); err != nil {
-		return err
+		return nil, err
 	}
 
-	if err := d.Run([]string{"./run.sh"}, handler); err != nil {
-		return err
+	results := newResultHandler(handler)
+
+	if err := d.Run([]string{"./run.sh"}, results); err != nil {
+		return results.result, err
 	}
 
-	return nil
+	return results.result, nil
 }
@@ -0,0 +1,29 @@
+// Package runnertest holds helpers for testing runner.Commands.
+package runnertest
+
+import (
+	"io"
+	"testing"
+
+	"github.com/abklabs/svmkit/pkg/runner"
+	"github.com/stretchr/testify/require"
+)
+
+// PayloadFiles adds cmd to a payload and returns the contents of the
+// payload's files by path.
+func PayloadFiles(t *testing.T, cmd runner.Command) map[string]string {
+	t.Helper()
+
+	p := &runner.Payload{}
+	require.NoError(t, cmd.AddToPayload(p))
+
+	files := map[string]string{}
+	for _, f := range p.Files {
+		b, err := io.ReadAll(f.Reader)
+		require.NoError(t, err)
+
+		files[f.Path] = string(b)
+	}
+
+	return files
+}
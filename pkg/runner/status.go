@@ -0,0 +1,96 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/abklabs/svmkit/pkg/runner/deployer"
+)
+
+// StatusPrefix marks a line of script output carrying a status report,
+// as written by svmkit::status in lib.bash.
+const StatusPrefix = "svmkit::status "
+
+// ParseStatus decodes the last status report called name in a
+// command's output lines into v.  It returns false if the command
+// made no such report.
+func ParseStatus(lines []string, name string, v any) (bool, error) {
+	prefix := StatusPrefix + name + " "
+
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, prefix)), v); err != nil {
+			return false, fmt.Errorf("invalid '%s' status: %w", name, err)
+		}
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// Result holds the status reports a command made while it ran.
+type Result struct {
+	// Lines are the command's output lines carrying a status report.
+	Lines []string
+}
+
+// Status decodes the command's last status report called name into
+// v.  It returns false if the command made no such report.
+func (r *Result) Status(name string, v any) (bool, error) {
+	if r == nil {
+		return false, nil
+	}
+
+	return ParseStatus(r.Lines, name, v)
+}
+
+// resultHandler passes a command's output on to the handler it wraps,
+// collecting the status reports on stdout into result as it goes.
+type resultHandler struct {
+	deployer.DeployerHandler
+
+	result  *Result
+	mu      sync.Mutex
+	partial bytes.Buffer
+}
+
+func newResultHandler(handler deployer.DeployerHandler) *resultHandler {
+	return &resultHandler{DeployerHandler: handler, result: &Result{}}
+}
+
+func (h *resultHandler) IngestReaders(done chan<- struct{}, stdout io.Reader, stderr io.Reader) error {
+	return h.DeployerHandler.IngestReaders(done, io.TeeReader(stdout, h), stderr)
+}
+
+func (h *resultHandler) Write(b []byte) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.partial.Write(b)
+
+	for {
+		i := bytes.IndexByte(h.partial.Bytes(), '\n')
+
+		if i < 0 {
+			break
+		}
+
+		line := string(h.partial.Next(i + 1))
+
+		if strings.Contains(line, StatusPrefix) {
+			h.result.Lines = append(h.result.Lines, strings.TrimRight(line, "\r\n"))
+		}
+	}
+
+	return len(b), nil
+}
@@ -0,0 +1,69 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/abklabs/svmkit/pkg/runner/deployer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStatus(t *testing.T) {
+	type status struct {
+		Slot int `json:"slot"`
+	}
+
+	lines := []string{
+		"svmkit::status example {\"slot\": 1}",
+		"some other output",
+		"svmkit::status other {\"slot\": 2}",
+		"  svmkit::status example {\"slot\": 3}",
+	}
+
+	var s status
+
+	ok, err := ParseStatus(lines, "example", &s)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 3, s.Slot)
+
+	ok, err = ParseStatus(lines, "missing", &s)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = ParseStatus([]string{"svmkit::status example {"}, "example", &s)
+	assert.Error(t, err)
+}
+
+func TestResultHandler(t *testing.T) {
+	type status struct {
+		Slot int `json:"slot"`
+	}
+
+	logger := &deployer.LoggerHandler{LogCallback: func(string) {}}
+	handler := newResultHandler(logger)
+
+	stdout := strings.NewReader("starting\nsvmkit::status example {\"slot\": 1}\ndone\nsvmkit::status example {\"slot\": 2}\n")
+	stderr := strings.NewReader("svmkit::status example {\"slot\": 3}\n")
+
+	done := make(chan struct{})
+	require.NoError(t, handler.IngestReaders(done, stdout, stderr))
+	<-done
+
+	assert.Len(t, logger.Lines(), 5)
+	assert.Len(t, handler.result.Lines, 2)
+
+	var s status
+
+	ok, err := handler.result.Status("example", &s)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 2, s.Slot)
+
+	var r *Result
+
+	ok, err = r.Status("example", &s)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
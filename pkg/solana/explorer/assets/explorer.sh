@@ -1,6 +1,9 @@
 # -*- mode: shell-script -*-
 # shellcheck shell=bash
 
+# shellcheck disable=SC1091
+. ./logging-lib.sh
+
 EXPLORER_SERVICE=svmkit-solana-explorer.service
 
 step::000::wait-for-a-stable-environment() {
@@ -16,8 +19,12 @@ step::004::create-sol-user() {
     create-sol-user
 }
 
+step::005::configure-logging() {
+    logging::install "${EXPLORER_SERVICE%.service}"
+}
+
 step::006::setup-explorer() {
-    svmkit::sudo chown -R sol:sol /opt/svmkit-solana-explorer
+    svmkit::sudo chown -R "$SVMKIT_USER:$SVMKIT_GROUP" /opt/svmkit-solana-explorer
 }
 
 step::007::setup-explorer-startup() {
@@ -33,7 +40,7 @@ $EXPLORER_ENV exec pnpm start /opt/svmkit-solana-explorer $EXPLORER_FLAGS
 EOF
 
     svmkit::sudo chmod 755 /opt/svmkit-solana-explorer/run-explorer
-    svmkit::sudo chown sol:sol /opt/svmkit-solana-explorer/run-explorer
+    svmkit::sudo chown "$SVMKIT_USER:$SVMKIT_GROUP" /opt/svmkit-solana-explorer/run-explorer
 
     cat <<EOF | svmkit::sudo tee /etc/systemd/system/"${EXPLORER_SERVICE}" >/dev/null
 [Unit]
@@ -41,8 +48,8 @@ Description=SVMkit Solana Explorer
 
 [Service]
 Type=exec
-User=sol
-Group=sol
+User=${SVMKIT_USER}
+Group=${SVMKIT_GROUP}
 ExecStart=/opt/svmkit-solana-explorer/run-explorer
 
 [Install]
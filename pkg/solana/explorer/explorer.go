@@ -3,6 +3,8 @@ package explorer
 import (
 	"strings"
 
+	"github.com/abklabs/svmkit/pkg/logging"
+	"github.com/abklabs/svmkit/pkg/machine/user"
 	"github.com/abklabs/svmkit/pkg/runner"
 	"github.com/abklabs/svmkit/pkg/runner/deb"
 	"github.com/abklabs/svmkit/pkg/solana"
@@ -41,6 +43,7 @@ func (cmd *ExplorerCommand) Env() *runner.EnvBuilder {
 	b.SetIntP("EXPLORER_PORT", cmd.Flags.Port)
 
 	b.Merge(cmd.RunnerCommand.Env())
+	b.Merge(cmd.ServiceUser.Env())
 
 	return b
 
@@ -49,6 +52,14 @@ func (cmd *ExplorerCommand) Env() *runner.EnvBuilder {
 func (cmd *ExplorerCommand) Check() error {
 	cmd.SetConfigDefaults()
 
+	if err := cmd.ServiceUser.Check(); err != nil {
+		return err
+	}
+
+	if err := cmd.Logging.CheckFor(logging.ProfileExplorer); err != nil {
+		return err
+	}
+
 	pkgGrp := deb.Package{}.MakePackageGroup("nodejs", "npm")
 	pkgGrp.Add(deb.Package{Name: "svmkit-solana-explorer", Version: cmd.Version})
 
@@ -68,6 +79,16 @@ func (cmd *ExplorerCommand) AddToPayload(p *runner.Payload) error {
 
 	p.AddReader(runner.ScriptNameSteps, explorerScript)
 
+	logConfig, err := cmd.Logging.Resolve(logging.ProfileExplorer)
+
+	if err != nil {
+		return err
+	}
+
+	if err := logConfig.AddToPayload(p, "svmkit-solana-explorer"); err != nil {
+		return err
+	}
+
 	if err := cmd.RunnerCommand.AddToPayload(p); err != nil {
 		return err
 	}
@@ -85,6 +106,8 @@ type Explorer struct {
 	Symbol      *string            `pulumi:"symbol,optional"`
 	ClusterName *string            `pulumi:"clusterName,optional"`
 	RPCURL      *string            `pulumi:"RPCURL,optional"`
+	ServiceUser *user.ServiceUser  `pulumi:"serviceUser,optional"`
+	Logging     *logging.Config    `pulumi:"logging,optional"`
 }
 
 func (f *Explorer) Install() runner.Command {
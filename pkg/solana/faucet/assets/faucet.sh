@@ -1,6 +1,9 @@
 # -*- mode: shell-script -*-
 # shellcheck shell=bash
 
+# shellcheck disable=SC1091
+. ./logging-lib.sh
+
 FAUCET_SERVICE=svmkit-solana-faucet.service
 
 step::000::wait-for-a-stable-environment() {
@@ -15,9 +18,13 @@ step::004::create-sol-user() {
     create-sol-user
 }
 
+step::005::configure-logging() {
+    logging::install "${FAUCET_SERVICE%.service}"
+}
+
 step::006::copy-faucet-keys() {
-    svmkit::sudo cp faucet-keypair.json /home/sol
-    svmkit::sudo chown sol:sol /home/sol/faucet-keypair.json
+    svmkit::sudo cp faucet-keypair.json "$SVMKIT_HOME"
+    svmkit::sudo chown "$SVMKIT_USER:$SVMKIT_GROUP" "$SVMKIT_HOME/faucet-keypair.json"
 }
 
 step::007::setup-faucet-startup() {
@@ -25,14 +32,14 @@ step::007::setup-faucet-startup() {
         svmkit::sudo systemctl stop "${FAUCET_SERVICE}" || true
     fi
 
-    cat <<EOF | svmkit::sudo tee /home/sol/run-faucet >/dev/null
+    cat <<EOF | svmkit::sudo tee "$SVMKIT_HOME/run-faucet" >/dev/null
 #!/usr/bin/env bash
 
 $FAUCET_ENV exec solana-faucet $FAUCET_FLAGS
 EOF
 
-    svmkit::sudo chmod 755 /home/sol/run-faucet
-    svmkit::sudo chown sol:sol /home/sol/run-faucet
+    svmkit::sudo chmod 755 "$SVMKIT_HOME/run-faucet"
+    svmkit::sudo chown "$SVMKIT_USER:$SVMKIT_GROUP" "$SVMKIT_HOME/run-faucet"
 
     cat <<EOF | svmkit::sudo tee /etc/systemd/system/"${FAUCET_SERVICE}" >/dev/null
 [Unit]
@@ -40,9 +47,9 @@ Description=SVMkit Solana Faucet
 
 [Service]
 Type=exec
-User=sol
-Group=sol
-ExecStart=/home/sol/run-faucet
+User=$SVMKIT_USER
+Group=$SVMKIT_GROUP
+ExecStart=$SVMKIT_HOME/run-faucet
 
 [Install]
 WantedBy=default.target
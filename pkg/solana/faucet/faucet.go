@@ -3,14 +3,12 @@ package faucet
 import (
 	"strings"
 
+	"github.com/abklabs/svmkit/pkg/logging"
+	"github.com/abklabs/svmkit/pkg/machine/user"
 	"github.com/abklabs/svmkit/pkg/runner"
 	"github.com/abklabs/svmkit/pkg/runner/deb"
 )
 
-const (
-	faucetKeyPairPath = "/home/sol/faucet-keypair.json"
-)
-
 type InstallCommand struct {
 	Faucet
 }
@@ -22,7 +20,7 @@ func (cmd *InstallCommand) Env() *runner.EnvBuilder {
 	b := runner.NewEnvBuilder()
 
 	b.SetMap(map[string]string{
-		"FAUCET_FLAGS": strings.Join(cmd.Flags.Args(), " "),
+		"FAUCET_FLAGS": strings.Join(cmd.Flags.ArgsWithKeyPair(cmd.KeyPairPath()), " "),
 		"FAUCET_ENV":   faucetEnv.String(),
 	})
 
@@ -31,6 +29,7 @@ func (cmd *InstallCommand) Env() *runner.EnvBuilder {
 	b.SetP("FAUCET_VERSION", cmd.Version)
 
 	b.Merge(cmd.RunnerCommand.Env())
+	b.Merge(cmd.ServiceUser.Env())
 
 	return b
 
@@ -39,6 +38,14 @@ func (cmd *InstallCommand) Env() *runner.EnvBuilder {
 func (cmd *InstallCommand) Check() error {
 	cmd.SetConfigDefaults()
 
+	if err := cmd.ServiceUser.Check(); err != nil {
+		return err
+	}
+
+	if err := cmd.Logging.CheckFor(logging.ProfileFaucet); err != nil {
+		return err
+	}
+
 	pkgGrp := deb.Package{}.MakePackageGroup()
 
 	pkgGrp.Add(deb.Package{Name: "svmkit-solana-faucet", Version: cmd.Version})
@@ -60,6 +67,16 @@ func (cmd *InstallCommand) AddToPayload(p *runner.Payload) error {
 	p.AddReader(runner.ScriptNameSteps, faucetScript)
 	p.AddString("faucet-keypair.json", cmd.KeyPair)
 
+	logConfig, err := cmd.Logging.Resolve(logging.ProfileFaucet)
+
+	if err != nil {
+		return err
+	}
+
+	if err := logConfig.AddToPayload(p, "svmkit-solana-faucet"); err != nil {
+		return err
+	}
+
 	if err := cmd.RunnerCommand.AddToPayload(p); err != nil {
 		return err
 	}
@@ -73,10 +90,18 @@ type Faucet struct {
 	Flags   FaucetFlags `pulumi:"flags"`
 	Version *string     `pulumi:"version,optional"`
 	KeyPair string      `pulumi:"keypair" provider:"secret"`
+
+	ServiceUser *user.ServiceUser `pulumi:"serviceUser,optional"`
+	Logging     *logging.Config   `pulumi:"logging,optional"`
 }
 
 func (f *Faucet) Args() []string {
-	return f.Flags.Args()
+	return f.Flags.ArgsWithKeyPair(f.KeyPairPath())
+}
+
+// KeyPairPath is where the faucet's keypair is installed.
+func (f *Faucet) KeyPairPath() string {
+	return f.ServiceUser.Path("faucet-keypair.json")
 }
 
 func (f *Faucet) Install() runner.Command {
@@ -104,9 +129,13 @@ type FaucetFlags struct {
 }
 
 func (f *FaucetFlags) Args() []string {
+	return f.ArgsWithKeyPair((&user.ServiceUser{}).Path("faucet-keypair.json"))
+}
+
+func (f *FaucetFlags) ArgsWithKeyPair(keyPairPath string) []string {
 	b := runner.FlagBuilder{}
 
-	b.Append("keypair", faucetKeyPairPath)
+	b.Append("keypair", keyPairPath)
 	b.AppendArrayP("allow-ip", f.AllowIPs)
 	b.AppendIntP("per-request-cap", f.PerRequestCap)
 	b.AppendIntP("per-time-cap", f.PerTimeCap)
@@ -71,21 +71,19 @@ step::020::fetch-all-programs() {
 }
 
 step::030::write-primordial-accounts-file() {
-    svmkit::sudo cp -f primordial.yaml /home/sol/primordial.yaml
-    svmkit::sudo chown sol:sol /home/sol/primordial.yaml
+    svmkit::sudo install -m 644 -o "$SVMKIT_USER" -g "$SVMKIT_GROUP" primordial.yaml "$SVMKIT_HOME/primordial.yaml"
 }
 
 step::035::write-validator-accounts-file() {
     if [[ -f validator_accounts.yaml ]]; then
-        svmkit::sudo cp -f validator_accounts.yaml /home/sol/validator_accounts.yaml
-        svmkit::sudo chown sol:sol /home/sol/validator_accounts.yaml
+        svmkit::sudo install -m 644 -o "$SVMKIT_USER" -g "$SVMKIT_GROUP" validator_accounts.yaml "$SVMKIT_HOME/validator_accounts.yaml"
     fi
 }
 
 step::040::execute-solana-genesis() {
-    svmkit::sudo -u sol "${GENESIS_ENV[@]}" solana-genesis "${GENESIS_FLAGS[@]}" "${genesis_args[@]}"
+    svmkit::sudo -u "$SVMKIT_USER" "${GENESIS_ENV[@]}" solana-genesis "${GENESIS_FLAGS[@]}" "${genesis_args[@]}"
 }
 
 step::050::create-initial-snapshot() {
-    svmkit::sudo -u sol -i agave-ledger-tool create-snapshot ROOT
+    svmkit::sudo -u "$SVMKIT_USER" -i agave-ledger-tool create-snapshot ROOT
 }
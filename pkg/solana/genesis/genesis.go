@@ -7,15 +7,16 @@ import (
 	"strconv"
 
 	"github.com/abklabs/svmkit/pkg/deletion"
+	"github.com/abklabs/svmkit/pkg/machine/user"
 	"github.com/abklabs/svmkit/pkg/runner"
 	"github.com/abklabs/svmkit/pkg/runner/deb"
 	"gopkg.in/yaml.v3"
 )
 
 const (
-	primordialAccountPath  = "/home/sol/primordial.yaml"
+	primordialAccountFile  = "primordial.yaml"
 	primordialDefaultOwner = "11111111111111111111111111111111"
-	validatorAccountsPath  = "/home/sol/validator_accounts.yaml"
+	validatorAccountsFile  = "validator_accounts.yaml"
 )
 
 type CreateCommand struct {
@@ -27,11 +28,12 @@ func (cmd *CreateCommand) Env() *runner.EnvBuilder {
 
 	b := runner.NewEnvBuilder()
 
-	b.SetArray("GENESIS_FLAGS", cmd.Flags.Args(cmd.Accounts))
+	b.SetArray("GENESIS_FLAGS", cmd.Flags.ArgsForUser(cmd.ServiceUser, cmd.Accounts))
 	b.SetArray("GENESIS_ENV", genesisEnv.Args())
 	b.Set("LEDGER_PATH", cmd.Flags.LedgerPath)
 
 	b.Merge(cmd.RunnerCommand.Env())
+	b.Merge(cmd.ServiceUser.Env())
 
 	cmd.DeletionPolicy.Create(&cmd.Genesis, b)
 
@@ -39,6 +41,10 @@ func (cmd *CreateCommand) Env() *runner.EnvBuilder {
 }
 
 func (cmd *CreateCommand) Check() error {
+	if err := cmd.ServiceUser.Check(); err != nil {
+		return err
+	}
+
 	if cmd.Flags.HashesPerTick != nil {
 		value := *cmd.Flags.HashesPerTick
 		switch value {
@@ -79,7 +85,7 @@ func (g *Genesis) Create() runner.Command {
 
 func (cmd *CreateCommand) AddToPayload(p *runner.Payload) error {
 	err := cmd.BuildPrimordialYaml(p.NewWriter(runner.PayloadFile{
-		Path: "primordial.yaml",
+		Path: primordialAccountFile,
 	}))
 
 	if err != nil {
@@ -88,7 +94,7 @@ func (cmd *CreateCommand) AddToPayload(p *runner.Payload) error {
 
 	if len(cmd.Accounts) > 0 {
 		err = cmd.BuildAccountsYaml(p.NewWriter(runner.PayloadFile{
-			Path: "validator_accounts.yaml",
+			Path: validatorAccountsFile,
 		}))
 
 		if err != nil {
@@ -212,6 +218,7 @@ func (d *DeleteCommand) Env() *runner.EnvBuilder {
 	b := runner.NewEnvBuilder()
 
 	b.Merge(d.RunnerCommand.Env())
+	b.Merge(d.ServiceUser.Env())
 
 	d.DeletionPolicy.Delete(&d.Genesis, b)
 
@@ -256,6 +263,7 @@ type Genesis struct {
 	Accounts       []BootstrapAccount  `pulumi:"accounts,optional"`
 	Version        *string             `pulumi:"version,optional"`
 	DeletionPolicy *deletion.Policy    `pulumi:"deletionPolicy,optional"`
+	ServiceUser    *user.ServiceUser   `pulumi:"serviceUser,optional"`
 }
 
 func (g *Genesis) GetDeletionPolicy() deletion.Policy {
@@ -300,21 +308,29 @@ type GenesisFlags struct {
 	ExtraFlags                      *[]string            `pulumi:"extraFlags,optional"`
 }
 
+// Args returns the flags for a genesis run as the default service
+// user.
 func (f GenesisFlags) Args(accounts []BootstrapAccount) []string {
+	return f.ArgsForUser(nil, accounts)
+}
+
+// ArgsForUser returns the flags for a genesis run as serviceUser,
+// whose home directory the accounts files are installed in.
+func (f GenesisFlags) ArgsForUser(serviceUser *user.ServiceUser, accounts []BootstrapAccount) []string {
 	b := runner.FlagBuilder{}
 
 	// Note: --upgradeable-program, --bpf-program are hard-coded in the install
 	// script and should not be included here.
 
 	// Required flags
-	b.Append("primordial-accounts-file", primordialAccountPath)
+	b.Append("primordial-accounts-file", serviceUser.Path(primordialAccountFile))
 
 	for _, validator := range f.BootstrapValidators {
 		b.AppendRaw("--bootstrap-validator", validator.IdentityPubkey, validator.VotePubkey, validator.StakePubkey)
 	}
 
 	if len(accounts) > 0 {
-		b.Append("validator-accounts-file", validatorAccountsPath)
+		b.Append("validator-accounts-file", serviceUser.Path(validatorAccountsFile))
 	}
 
 	b.Append("ledger", f.LedgerPath)
@@ -3,6 +3,7 @@ package genesis
 import (
 	"testing"
 
+	"github.com/abklabs/svmkit/pkg/machine/user"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -88,9 +89,9 @@ func TestGenesisFlags(t *testing.T) {
 
 	// Construct the expected argument list
 	expectedArgs := []string{
-		"--primordial-accounts-file", primordialAccountPath,
+		"--primordial-accounts-file", "/home/sol/primordial.yaml",
 		"--bootstrap-validator", identityPubkey, votePubkey, stakePubkey,
-		"--validator-accounts-file", validatorAccountsPath,
+		"--validator-accounts-file", "/home/sol/validator_accounts.yaml",
 		"--ledger", ledgerPath,
 		"--bootstrap-stake-authorized-pubkey", "bootstrap_auth_key",
 		"--bootstrap-validator-lamports", "5000000000",
@@ -121,3 +122,17 @@ func TestGenesisFlags(t *testing.T) {
 
 	assert.Equal(t, expectedArgs, actualArgs)
 }
+
+func TestGenesisFlagsForUser(t *testing.T) {
+	name := "solana"
+	home := "/srv/solana"
+	f := GenesisFlags{LedgerPath: "/srv/solana/ledger"}
+
+	args := f.ArgsForUser(&user.ServiceUser{Name: &name, Home: &home}, []BootstrapAccount{{IdentityPubkey: "identity"}})
+
+	assert.Equal(t, []string{
+		"--primordial-accounts-file", "/srv/solana/primordial.yaml",
+		"--validator-accounts-file", "/srv/solana/validator_accounts.yaml",
+		"--ledger", "/srv/solana/ledger",
+	}, args)
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/abklabs/svmkit/pkg/logging"
+	"github.com/abklabs/svmkit/pkg/machine/user"
 	"github.com/abklabs/svmkit/pkg/runner"
 	"github.com/abklabs/svmkit/pkg/runner/deb"
 	"github.com/abklabs/svmkit/pkg/solana"
@@ -46,6 +48,7 @@ func (cmd *InstallCommand) Env() *runner.EnvBuilder {
 	})
 
 	b.Merge(cmd.RunnerCommand.Env())
+	b.Merge(cmd.ServiceUser.Env())
 
 	return b
 
@@ -54,6 +57,14 @@ func (cmd *InstallCommand) Env() *runner.EnvBuilder {
 func (cmd *InstallCommand) Check() error {
 	cmd.SetConfigDefaults()
 
+	if err := cmd.ServiceUser.Check(); err != nil {
+		return err
+	}
+
+	if err := cmd.Logging.CheckFor(logging.ProfileWatchtower); err != nil {
+		return err
+	}
+
 	grp := deb.Package{}.MakePackageGroup("svmkit-agave-watchtower")
 
 	if err := cmd.UpdatePackageGroup(grp); err != nil {
@@ -70,6 +81,16 @@ func (cmd *InstallCommand) AddToPayload(p *runner.Payload) error {
 		return err
 	}
 
+	logConfig, err := cmd.Logging.Resolve(logging.ProfileWatchtower)
+
+	if err != nil {
+		return err
+	}
+
+	if err := logConfig.AddToPayload(p, "svmkit-agave-watchtower"); err != nil {
+		return err
+	}
+
 	if err := cmd.RunnerCommand.AddToPayload(p); err != nil {
 		return err
 	}
@@ -83,6 +104,8 @@ type Watchtower struct {
 	Environment   solana.Environment `pulumi:"environment"`
 	Flags         WatchtowerFlags    `pulumi:"flags"`
 	Notifications NotificationConfig `pulumi:"notifications"`
+	ServiceUser   *user.ServiceUser  `pulumi:"serviceUser,optional"`
+	Logging       *logging.Config    `pulumi:"logging,optional"`
 }
 
 func (w *Watchtower) Args() []string {
@@ -1,6 +1,7 @@
 package tuner
 
 import (
+	"github.com/abklabs/svmkit/pkg/machine/user"
 	"github.com/abklabs/svmkit/pkg/runner"
 	"github.com/abklabs/svmkit/pkg/runner/deb"
 
@@ -23,6 +24,7 @@ func (cmd *TunerCommand) Env() *runner.EnvBuilder {
 	}
 
 	tunerEnv.Merge(cmd.RunnerCommand.Env())
+	tunerEnv.Merge(cmd.ServiceUser.Env())
 
 	return tunerEnv
 }
@@ -30,6 +32,10 @@ func (cmd *TunerCommand) Env() *runner.EnvBuilder {
 func (cmd *TunerCommand) Check() error {
 	cmd.SetConfigDefaults()
 
+	if err := cmd.ServiceUser.Check(); err != nil {
+		return err
+	}
+
 	pkgGrp := deb.Package{}.MakePackageGroup("cpufrequtils")
 
 	if err := cmd.UpdatePackageGroup(pkgGrp); err != nil {
@@ -111,7 +117,8 @@ type TunerParams struct {
 
 type Tuner struct {
 	runner.RunnerCommand
-	Params TunerParams `pulumi:"params" toml:"params"`
+	Params      TunerParams       `pulumi:"params" toml:"params"`
+	ServiceUser *user.ServiceUser `pulumi:"serviceUser,optional" toml:"-"`
 }
 
 func (f *Tuner) Create() runner.Command {